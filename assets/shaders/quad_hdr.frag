@@ -0,0 +1,25 @@
+#version 330 core
+in vec2 pass_uv;
+
+out vec4 out_color;
+
+uniform sampler2D u_tex;
+uniform float u_exposure;
+// 0: clamp, 1: Reinhard, 2: ACES
+uniform int u_tonemap;
+uniform float u_gamma;
+
+#include "tonemap.glsl"
+
+void main()
+{
+    vec4 texel = texture(u_tex, pass_uv);
+    vec3 color = texel.rgb * exp2(u_exposure);
+    if (u_tonemap == 1) {
+        color = reinhard(color);
+    } else if (u_tonemap == 2) {
+        color = aces(color);
+    }
+    color = pow(clamp(color, 0., 1.), vec3(1. / u_gamma));
+    out_color = vec4(color, texel.a);
+}
\ No newline at end of file
@@ -0,0 +1,13 @@
+#version 330 core
+in vec2 pass_uv;
+
+out vec4 out_color;
+
+uniform sampler2D u_tex;
+uniform sampler1D u_palette;
+
+void main()
+{
+    int index = int(texture(u_tex, pass_uv).r * 255. + .5);
+    out_color = texelFetch(u_palette, index, 0);
+}
\ No newline at end of file
@@ -0,0 +1,10 @@
+// Narkowicz 2015, ACES Filmic Tone Mapping Curve
+vec3 aces(vec3 x)
+{
+    return (x * (2.51 * x + .03)) / (x * (2.43 * x + .59) + .14);
+}
+
+vec3 reinhard(vec3 x)
+{
+    return x / (1. + x);
+}
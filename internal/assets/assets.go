@@ -0,0 +1,144 @@
+//go:generate go run gen.go
+
+package assets
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Qendolin/go-printpixel/internal/utils"
+)
+
+//A Source provides asset files by their slash separated path, e.g. "assets/shaders/quad_tex.vert"
+type Source interface {
+	Open(name string) (io.ReadCloser, error)
+}
+
+//Implemented by sources whose files can change
+type ModTimer interface {
+	ModTime(name string) (time.Time, error)
+}
+
+//The source used when no other source is specified. Files that are not built in are read relative to the module root.
+var Default Source = Overlay{Builtin, moduleDir{}}
+
+//The assets that are compiled into the binary. See gen.go
+var Builtin Source = mapSource(builtinFiles)
+
+type mapSource map[string]string
+
+func (src mapSource) Open(name string) (io.ReadCloser, error) {
+	content, ok := src[path.Clean(name)]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return ioutil.NopCloser(strings.NewReader(content)), nil
+}
+
+//A directory of the file system
+type Dir string
+
+func (dir Dir) Open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(dir), filepath.FromSlash(path.Clean("/"+name))))
+}
+
+func (dir Dir) ModTime(name string) (time.Time, error) {
+	info, err := os.Stat(filepath.Join(string(dir), filepath.FromSlash(path.Clean("/"+name))))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+//The directory of the module, resolved when a file is accessed. See utils.ResolveModulePath
+type moduleDir struct{}
+
+func (moduleDir) Open(name string) (io.ReadCloser, error) {
+	root, err := utils.ResolveModulePath("")
+	if err != nil {
+		return nil, err
+	}
+	return Dir(root).Open(name)
+}
+
+func (moduleDir) ModTime(name string) (time.Time, error) {
+	root, err := utils.ResolveModulePath("")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return Dir(root).ModTime(name)
+}
+
+//Opens a file from the first source that has it, so earlier sources override later ones
+type Overlay []Source
+
+func (overlay Overlay) Open(name string) (io.ReadCloser, error) {
+	for _, src := range overlay {
+		file, err := src.Open(name)
+		if err == nil {
+			return file, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+}
+
+//The modification time of the file the overlay opens for name
+func (overlay Overlay) ModTime(name string) (time.Time, error) {
+	for _, src := range overlay {
+		modTime, err := ModTime(src, name)
+		if err == nil {
+			return modTime, nil
+		}
+		if !os.IsNotExist(err) {
+			return time.Time{}, err
+		}
+	}
+	return time.Time{}, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+/*
+	Returns when a file was last modified. Absolute paths are checked on the file system instead.
+	Files of sources that do not implement ModTimer never change, their time is zero.
+*/
+func ModTime(src Source, name string) (time.Time, error) {
+	if filepath.IsAbs(name) {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return info.ModTime(), nil
+	}
+	if timer, ok := src.(ModTimer); ok {
+		return timer.ModTime(name)
+	}
+	file, err := src.Open(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Time{}, file.Close()
+}
+
+//Reads a whole file from src. Absolute paths are read from the file system instead.
+func ReadFile(src Source, name string) ([]byte, error) {
+	if filepath.IsAbs(name) {
+		return ioutil.ReadFile(name)
+	}
+	file, err := src.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(file)
+	return buf.Bytes(), err
+}
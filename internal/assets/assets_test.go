@@ -0,0 +1,117 @@
+package assets_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Qendolin/go-printpixel/internal/assets"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/Qendolin/go-printpixel/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	test.ParseArgs()
+	m.Run()
+}
+
+func TestBuiltinMatchesFiles(t *testing.T) {
+	files, err := filepath.Glob(utils.MustResolveModulePath("assets/shaders/*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		expected, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := "assets/shaders/" + filepath.Base(file)
+		actual, err := assets.ReadFile(assets.Builtin, name)
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual), "%v is outdated, run go generate", name)
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "printpixel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := "assets/shaders/quad_tex.frag"
+	if err = os.MkdirAll(filepath.Join(dir, "assets/shaders"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, name), []byte("override"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := assets.Overlay{assets.Dir(dir), assets.Builtin}
+	content, err := assets.ReadFile(src, name)
+	assert.NoError(t, err)
+	assert.Equal(t, "override", string(content))
+
+	content, err = assets.ReadFile(src, "assets/shaders/quad_tex.vert")
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "#version 330 core")
+
+	_, err = assets.ReadFile(src, "assets/missing.txt")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestModTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "printpixel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "changed.glsl")
+	if err = ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err = os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	src := assets.Overlay{assets.Dir(dir), assets.Builtin}
+	actual, err := assets.ModTime(src, "changed.glsl")
+	assert.NoError(t, err)
+	assert.True(t, modTime.Equal(actual), "%v", actual)
+
+	actual, err = assets.ModTime(src, "assets/shaders/quad_tex.vert")
+	assert.NoError(t, err)
+	assert.True(t, actual.IsZero())
+
+	actual, err = assets.ModTime(assets.Builtin, file)
+	assert.NoError(t, err)
+	assert.True(t, modTime.Equal(actual), "absolute paths")
+
+	_, err = assets.ModTime(src, "assets/missing.txt")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDefault(t *testing.T) {
+	content, err := assets.ReadFile(assets.Default, "assets/shaders/quad_tex.vert")
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "#version 330 core")
+
+	//Files that are not built in are read relative to the module root
+	expected, err := ioutil.ReadFile(utils.MustResolveModulePath("go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err = assets.ReadFile(assets.Default, "go.mod")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, content)
+
+	_, err = assets.ReadFile(assets.Default, "assets/missing.txt")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,15 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+package assets
+
+var builtinFiles = map[string]string{
+	"assets/shaders/quad_hdr.frag":     "#version 330 core\nin vec2 pass_uv;\n\nout vec4 out_color;\n\nuniform sampler2D u_tex;\nuniform float u_exposure;\n// 0: clamp, 1: Reinhard, 2: ACES\nuniform int u_tonemap;\nuniform float u_gamma;\n\n#include \"tonemap.glsl\"\n\nvoid main()\n{\n    vec4 texel = texture(u_tex, pass_uv);\n    vec3 color = texel.rgb * exp2(u_exposure);\n    if (u_tonemap == 1) {\n        color = reinhard(color);\n    } else if (u_tonemap == 2) {\n        color = aces(color);\n    }\n    color = pow(clamp(color, 0., 1.), vec3(1. / u_gamma));\n    out_color = vec4(color, texel.a);\n}",
+	"assets/shaders/quad_palette.frag": "#version 330 core\nin vec2 pass_uv;\n\nout vec4 out_color;\n\nuniform sampler2D u_tex;\nuniform sampler1D u_palette;\n\nvoid main()\n{\n    int index = int(texture(u_tex, pass_uv).r * 255. + .5);\n    out_color = texelFetch(u_palette, index, 0);\n}",
+	"assets/shaders/quad_tex.frag":     "#version 330 core\nin vec2 pass_uv;\n\nout vec4 out_color;\n\nuniform sampler2D u_tex;\n\nvoid main()\n{\n    out_color = texture(u_tex, pass_uv);\n} ",
+	"assets/shaders/quad_tex.vert":     "#version 330 core\nlayout (location = 0) in vec2 in_position;\n\nout vec2 pass_uv;\n\nvoid main()\n{\n    gl_Position = vec4(in_position, 0., 1.);\n    pass_uv = in_position*vec2(1,-1)*0.5+vec2(0.5);\n}",
+	"assets/shaders/quad_uniform.frag": "#version 330 core\nout vec4 out_color;\nuniform vec3 u_color;\n\nvoid main()\n{\n    out_color = vec4(u_color, 1.);\n} ",
+	"assets/shaders/quad_uniform.vert": "#version 330 core\nlayout (location = 0) in vec2 in_position;\nout vec3 pass_color;\n\nvoid main()\n{\n    gl_Position = vec4(in_position, 0., 1.);\n}",
+	"assets/shaders/quad_uv.frag":      "#version 330 core\nout vec4 out_color;\nin vec3 pass_color;\n\nvoid main()\n{\n    out_color = vec4(pass_color, 1.);\n} ",
+	"assets/shaders/quad_uv.vert":      "#version 330 core\nlayout (location = 0) in vec2 in_position;\nout vec3 pass_color;\n\nvoid main()\n{\n    gl_Position = vec4(in_position, 0., 1.);\n    pass_color = vec3(in_position, 0.);\n}",
+	"assets/shaders/tonemap.glsl":      "// Narkowicz 2015, ACES Filmic Tone Mapping Curve\nvec3 aces(vec3 x)\n{\n    return (x * (2.51 * x + .03)) / (x * (2.43 * x + .59) + .14);\n}\n\nvec3 reinhard(vec3 x)\n{\n    return x / (1. + x);\n}\n",
+}
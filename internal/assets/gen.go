@@ -0,0 +1,49 @@
+// +build ignore
+
+//Generates builtin.go from the shaders in the assets directory
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path"
+	"path/filepath"
+)
+
+const assetDir = "../../assets"
+
+var patterns = []string{"shaders/*"}
+
+func main() {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go. DO NOT EDIT.\n\npackage assets\n\nvar builtinFiles = map[string]string{\n")
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(filepath.Join(assetDir, pattern))
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, file := range files {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				log.Fatal(err)
+			}
+			rel, err := filepath.Rel(assetDir, file)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Fprintf(&buf, "%q: %q,\n", path.Join("assets", filepath.ToSlash(rel)), content)
+		}
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile("builtin.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
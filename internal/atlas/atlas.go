@@ -0,0 +1,271 @@
+//Package atlas packs many small images into a few large pages, so they can be drawn from one texture.
+package atlas
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var ErrNoImages = errors.New("The atlas has no images")
+
+type DuplicateErr struct {
+	Name string
+}
+
+func (derr DuplicateErr) Error() string {
+	return fmt.Sprintf("An image named '%v' was already added", derr.Name)
+}
+
+type TooLargeErr struct {
+	Name string
+	Size image.Point
+}
+
+func (tlerr TooLargeErr) Error() string {
+	return fmt.Sprintf("Image '%v' of size %v doesn't fit into a page", tlerr.Name, tlerr.Size)
+}
+
+//The location of an image in the atlas
+type Region struct {
+	//The index of the page
+	Page int
+	//The pixels of the image in the page, without padding and extrusion
+	Rect image.Rectangle
+	//The texture coordinates of Rect, 0,0 is the top left corner of the page as it is uploaded top-down
+	UvMin, UvMax mgl32.Vec2
+}
+
+type Atlas struct {
+	Pages   []*image.RGBA
+	Regions map[string]Region
+}
+
+//Collects images and packs them into pages
+type Builder struct {
+	//The initial size of each page
+	PageWidth, PageHeight int
+	//Full pages are doubled in size up to this size before a new page is added. No growing if 0.
+	MaxPageWidth, MaxPageHeight int
+	//The transparent space between images and around the page border
+	Padding int
+	//The number of times the edge pixels of each image are repeated outwards, which prevents bleeding when filtering
+	Extrude int
+	images  []entry
+	names   map[string]bool
+}
+
+type entry struct {
+	name string
+	img  image.Image
+	//The size including extrusion and padding
+	cell image.Point
+}
+
+func NewBuilder(pageWidth, pageHeight int) *Builder {
+	return &Builder{
+		PageWidth:  pageWidth,
+		PageHeight: pageHeight,
+		names:      map[string]bool{},
+	}
+}
+
+func (b *Builder) Add(name string, img image.Image) error {
+	if b.names[name] {
+		return DuplicateErr{Name: name}
+	}
+	b.names[name] = true
+	b.images = append(b.images, entry{name: name, img: img})
+	return nil
+}
+
+type page struct {
+	width, height int
+	sky           *skyline
+	entries       []entry
+	positions     []image.Point
+}
+
+func (b *Builder) newPage() *page {
+	p := &page{width: b.PageWidth, height: b.PageHeight}
+	p.reset(b.Padding)
+	return p
+}
+
+func (p *page) reset(padding int) {
+	//The padding of the right and bottom border is reserved up front, each cell has padding at its left and top
+	p.sky = newSkyline(p.width-padding, p.height-padding)
+	p.entries = p.entries[:0]
+	p.positions = p.positions[:0]
+}
+
+func (p *page) insert(e entry) bool {
+	pos, ok := p.sky.insert(e.cell.X, e.cell.Y)
+	if ok {
+		p.entries = append(p.entries, e)
+		p.positions = append(p.positions, pos)
+	}
+	return ok
+}
+
+//Doubles the smaller side of the page and packs all entries again
+func (b *Builder) grow(p *page, e entry) bool {
+	maxWidth, maxHeight := b.maxSize()
+	entries := append(append([]entry(nil), p.entries...), e)
+	width, height := p.width, p.height
+	for p.width < maxWidth || p.height < maxHeight {
+		if p.width <= p.height && p.width < maxWidth || p.height >= maxHeight {
+			p.width = min(p.width*2, maxWidth)
+		} else {
+			p.height = min(p.height*2, maxHeight)
+		}
+
+		p.reset(b.Padding)
+		packed := true
+		for _, e := range entries {
+			if !p.insert(e) {
+				packed = false
+				break
+			}
+		}
+		if packed {
+			return true
+		}
+	}
+
+	//Restore the previous size and layout, the new entry goes to another page
+	p.width, p.height = width, height
+	p.reset(b.Padding)
+	for _, e := range entries[:len(entries)-1] {
+		p.insert(e)
+	}
+	return false
+}
+
+func (b *Builder) maxSize() (int, int) {
+	return max(b.MaxPageWidth, b.PageWidth), max(b.MaxPageHeight, b.PageHeight)
+}
+
+//Packs the images, the largest first, and draws the pages
+func (b *Builder) Build() (*Atlas, error) {
+	if len(b.images) == 0 {
+		return nil, ErrNoImages
+	}
+
+	maxWidth, maxHeight := b.maxSize()
+	entries := make([]entry, len(b.images))
+	for i, e := range b.images {
+		size := e.img.Bounds().Size()
+		e.cell = size.Add(image.Pt(2*b.Extrude+b.Padding, 2*b.Extrude+b.Padding))
+		if e.cell.X > maxWidth-b.Padding || e.cell.Y > maxHeight-b.Padding {
+			return nil, TooLargeErr{Name: e.name, Size: size}
+		}
+		entries[i] = e
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].cell.Y != entries[j].cell.Y {
+			return entries[i].cell.Y > entries[j].cell.Y
+		}
+		return entries[i].cell.X > entries[j].cell.X
+	})
+
+	var pages []*page
+	for _, e := range entries {
+		placed := false
+		for _, p := range pages {
+			if placed = p.insert(e); placed {
+				break
+			}
+		}
+		if !placed && len(pages) > 0 {
+			placed = b.grow(pages[len(pages)-1], e)
+		}
+		if !placed {
+			p := b.newPage()
+			if !p.insert(e) && !b.grow(p, e) {
+				//Can't happen, the entry fits into an empty page of the maximum size
+				return nil, TooLargeErr{Name: e.name, Size: e.img.Bounds().Size()}
+			}
+			pages = append(pages, p)
+		}
+	}
+
+	atlas := &Atlas{Regions: map[string]Region{}}
+	for i, p := range pages {
+		img := image.NewRGBA(image.Rect(0, 0, p.width, p.height))
+		for j, e := range p.entries {
+			min := p.positions[j].Add(image.Pt(b.Padding+b.Extrude, b.Padding+b.Extrude))
+			rect := image.Rectangle{Min: min, Max: min.Add(e.img.Bounds().Size())}
+			draw.Draw(img, rect, e.img, e.img.Bounds().Min, draw.Src)
+			extrude(img, rect, b.Extrude)
+			atlas.Regions[e.name] = Region{
+				Page:  i,
+				Rect:  rect,
+				UvMin: mgl32.Vec2{float32(rect.Min.X) / float32(p.width), float32(rect.Min.Y) / float32(p.height)},
+				UvMax: mgl32.Vec2{float32(rect.Max.X) / float32(p.width), float32(rect.Max.Y) / float32(p.height)},
+			}
+		}
+		atlas.Pages = append(atlas.Pages, img)
+	}
+	return atlas, nil
+}
+
+//Repeats the edge pixels of rect n times outwards
+func extrude(img *image.RGBA, rect image.Rectangle, n int) {
+	if n <= 0 || rect.Empty() {
+		return
+	}
+	outer := rect.Inset(-n)
+	for y := outer.Min.Y; y < outer.Max.Y; y++ {
+		for x := outer.Min.X; x < outer.Max.X; x++ {
+			if (image.Point{x, y}).In(rect) {
+				continue
+			}
+			sx := clamp(x, rect.Min.X, rect.Max.X-1)
+			sy := clamp(y, rect.Min.Y, rect.Max.Y-1)
+			img.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+}
+
+func (atlas *Atlas) Region(name string) (Region, bool) {
+	region, ok := atlas.Regions[name]
+	return region, ok
+}
+
+//Creates one texture per page with linear filtering. The textures are left bound to unit 0 in turn.
+func (atlas *Atlas) Upload() []*data.Texture {
+	textures := make([]*data.Texture, len(atlas.Pages))
+	for i, img := range atlas.Pages {
+		tex := data.NewTexture(data.Texture2D)
+		tex.Bind(0)
+		tex.FilterMode(data.FilterLinear, data.FilterLinear)
+		tex.WrapMode(data.WrapClampToEdge, data.WrapClampToEdge, 0)
+		tex.AllocWithImage(img, 0)
+		textures[i] = tex
+	}
+	return textures
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func clamp(v, low, high int) int {
+	return max(low, min(v, high))
+}
@@ -0,0 +1,123 @@
+package atlas_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/atlas"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	test.ParseArgs()
+	m.Run()
+}
+
+func filled(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+//Checks that all regions are inside their page and that their padded rectangles don't overlap
+func assertLayout(t *testing.T, a *atlas.Atlas, padding, extrude int) {
+	for name, region := range a.Regions {
+		page := a.Pages[region.Page].Rect
+		outer := region.Rect.Inset(-extrude)
+		assert.True(t, outer.Inset(-padding).In(page), "%v %v is outside of page %v", name, outer, page)
+		for other, otherRegion := range a.Regions {
+			if other == name || otherRegion.Page != region.Page {
+				continue
+			}
+			otherOuter := otherRegion.Rect.Inset(-extrude - padding)
+			assert.False(t, outer.Overlaps(otherOuter), "%v overlaps %v", name, other)
+		}
+	}
+}
+
+func TestPack(t *testing.T) {
+	b := atlas.NewBuilder(64, 64)
+	b.Padding = 1
+	sizes := []image.Point{{10, 20}, {30, 5}, {16, 16}, {8, 8}, {20, 10}, {5, 30}, {12, 12}, {7, 3}}
+	for i, size := range sizes {
+		assert.NoError(t, b.Add(fmt.Sprint(i), filled(size.X, size.Y, color.RGBA{uint8(i), 0, 0, 255})))
+	}
+	assert.Equal(t, atlas.DuplicateErr{Name: "0"}, b.Add("0", filled(1, 1, color.RGBA{})))
+
+	a, err := b.Build()
+	assert.NoError(t, err)
+	assert.Len(t, a.Pages, 1)
+	assert.Len(t, a.Regions, len(sizes))
+	assertLayout(t, a, 1, 0)
+
+	for i, size := range sizes {
+		region, ok := a.Region(fmt.Sprint(i))
+		assert.True(t, ok)
+		assert.Equal(t, size, region.Rect.Size())
+		assert.Equal(t, color.RGBA{uint8(i), 0, 0, 255}, a.Pages[0].RGBAAt(region.Rect.Min.X, region.Rect.Min.Y))
+		assert.Equal(t, mgl32.Vec2{float32(region.Rect.Max.X) / 64, float32(region.Rect.Max.Y) / 64}, region.UvMax)
+	}
+}
+
+func TestExtrude(t *testing.T) {
+	b := atlas.NewBuilder(16, 16)
+	b.Extrude = 2
+	img := filled(2, 2, color.RGBA{255, 0, 0, 255})
+	img.SetRGBA(1, 1, color.RGBA{0, 255, 0, 255})
+	assert.NoError(t, b.Add("img", img))
+
+	a, err := b.Build()
+	assert.NoError(t, err)
+	rect := a.Regions["img"].Rect
+	page := a.Pages[0]
+	assert.Equal(t, image.Pt(2, 2), rect.Min)
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, page.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{0, 255, 0, 255}, page.RGBAAt(5, 5))
+	assert.Equal(t, color.RGBA{0, 255, 0, 255}, page.RGBAAt(3, 5))
+	assert.Equal(t, color.RGBA{}, page.RGBAAt(6, 6))
+}
+
+func TestGrowAndPages(t *testing.T) {
+	b := atlas.NewBuilder(16, 16)
+	b.MaxPageWidth, b.MaxPageHeight = 32, 32
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, b.Add(fmt.Sprint(i), filled(16, 16, color.RGBA{255, 255, 255, 255})))
+	}
+
+	a, err := b.Build()
+	assert.NoError(t, err)
+	assert.Len(t, a.Pages, 2)
+	assert.Equal(t, image.Rect(0, 0, 32, 32), a.Pages[0].Rect)
+	assert.Equal(t, image.Rect(0, 0, 32, 16), a.Pages[1].Rect)
+	assertLayout(t, a, 0, 0)
+
+	b.Padding = 1
+	_, err = b.Build()
+	assert.NoError(t, err)
+
+	//Growing to the maximum size doesn't make room for a second image, so the first page keeps its size
+	b = atlas.NewBuilder(16, 16)
+	b.MaxPageWidth, b.MaxPageHeight = 24, 24
+	b.Add("a", filled(16, 16, color.RGBA{}))
+	b.Add("b", filled(16, 16, color.RGBA{}))
+	a, err = b.Build()
+	assert.NoError(t, err)
+	if assert.Len(t, a.Pages, 2) {
+		assert.Equal(t, image.Rect(0, 0, 16, 16), a.Pages[0].Rect)
+		assert.Equal(t, image.Rect(0, 0, 16, 16), a.Pages[1].Rect)
+	}
+
+	b = atlas.NewBuilder(16, 16)
+	b.Add("large", filled(17, 1, color.RGBA{}))
+	_, err = b.Build()
+	assert.Equal(t, atlas.TooLargeErr{Name: "large", Size: image.Pt(17, 1)}, err)
+
+	_, err = atlas.NewBuilder(16, 16).Build()
+	assert.Equal(t, atlas.ErrNoImages, err)
+}
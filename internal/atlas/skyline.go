@@ -0,0 +1,98 @@
+package atlas
+
+import "image"
+
+//A segment of the skyline, everything above y is occupied. y grows downwards.
+type skylineNode struct {
+	x, y, width int
+}
+
+//Packs rectangles bottom-left first, the lowest top edge wins
+type skyline struct {
+	width, height int
+	nodes         []skylineNode
+}
+
+func newSkyline(width, height int) *skyline {
+	return &skyline{
+		width:  width,
+		height: height,
+		nodes:  []skylineNode{{x: 0, y: 0, width: width}},
+	}
+}
+
+//Reserves a w by h rectangle and returns its position
+func (sky *skyline) insert(w, h int) (image.Point, bool) {
+	bestIndex := -1
+	var bestPos image.Point
+	bestWidth := 0
+	for i := range sky.nodes {
+		y, ok := sky.fit(i, w, h)
+		if !ok {
+			continue
+		}
+		node := sky.nodes[i]
+		if bestIndex == -1 || y < bestPos.Y || y == bestPos.Y && node.width < bestWidth {
+			bestIndex = i
+			bestPos = image.Pt(node.x, y)
+			bestWidth = node.width
+		}
+	}
+	if bestIndex == -1 {
+		return image.Point{}, false
+	}
+	sky.add(bestIndex, bestPos, w, h)
+	return bestPos, true
+}
+
+//Returns the y position of a rectangle whose left edge is at node i
+func (sky *skyline) fit(i, w, h int) (int, bool) {
+	x := sky.nodes[i].x
+	if x+w > sky.width {
+		return 0, false
+	}
+	y := 0
+	for left := w; left > 0; i++ {
+		if sky.nodes[i].y > y {
+			y = sky.nodes[i].y
+		}
+		if y+h > sky.height {
+			return 0, false
+		}
+		left -= sky.nodes[i].width
+	}
+	return y, true
+}
+
+func (sky *skyline) add(i int, pos image.Point, w, h int) {
+	node := skylineNode{x: pos.X, y: pos.Y + h, width: w}
+	sky.nodes = append(sky.nodes, skylineNode{})
+	copy(sky.nodes[i+1:], sky.nodes[i:])
+	sky.nodes[i] = node
+
+	//Shrink or remove the nodes that are now covered
+	for j := i + 1; j < len(sky.nodes); {
+		prev := sky.nodes[j-1]
+		cur := &sky.nodes[j]
+		overlap := prev.x + prev.width - cur.x
+		if overlap <= 0 {
+			break
+		}
+		cur.x += overlap
+		cur.width -= overlap
+		if cur.width > 0 {
+			break
+		}
+		sky.nodes = append(sky.nodes[:j], sky.nodes[j+1:]...)
+	}
+
+	//Merge neighbours at the same height
+	for j := 0; j < len(sky.nodes)-1; {
+		if sky.nodes[j].y == sky.nodes[j+1].y {
+			sky.nodes[j].width += sky.nodes[j+1].width
+			sky.nodes = append(sky.nodes[:j+1], sky.nodes[j+2:]...)
+		} else {
+			j++
+		}
+	}
+}
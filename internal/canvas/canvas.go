@@ -1,6 +1,9 @@
 package canvas
 
 import (
+	"image"
+	"image/color"
+
 	"github.com/Qendolin/go-printpixel/internal/data"
 	"github.com/Qendolin/go-printpixel/internal/shader"
 	"github.com/Qendolin/go-printpixel/internal/utils"
@@ -12,35 +15,48 @@ var (
 )
 
 type Canvas struct {
-	Program shader.Program
-	quad    data.Vao
+	Program   shader.Program
+	ScaleMode ScaleMode
+	//The color of the area not covered by the content, the clear color is used if nil
+	BarColor color.Color
+	quad     data.Vao
+	quadVbo  *data.Vbo
+	content  image.Point
+	frame    image.Point
 }
 
-func NewCanvas() *Canvas {
+//Creates a canvas that draws the texture bound to unit 0. The shaders are loaded from assets.Default.
+func NewCanvas() (*Canvas, error) {
 	vs, err := shader.NewShaderFromPath("assets/shaders/quad_tex.vert", shader.TypeVertex)
+	if vs != nil {
+		//Also returned if it failed to compile
+		defer vs.Destroy()
+	}
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	fs, err := shader.NewShaderFromPath("assets/shaders/quad_tex.frag", shader.TypeFragment)
+	if fs != nil {
+		defer fs.Destroy()
+	}
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	quadShaderProg, err := shader.NewProgram(vs, fs)
 	if err != nil {
-		panic(err)
+		quadShaderProg.Destroy()
+		return nil, err
 	}
 
-	fs.Destroy()
-	vs.Destroy()
-	return NewCanvasWithProgram(*quadShaderProg)
+	return NewCanvasWithProgram(*quadShaderProg), nil
 }
 
 func NewCanvasWithProgram(prog shader.Program) *Canvas {
 	quadVao := data.NewVao()
+	quadVbo := data.NewVbo()
 	quadVao.BindFor(func() (defered []func()) {
-		quadVbo := data.NewVbo()
 		quadVbo.Bind(gl.ARRAY_BUFFER)
 		quadVbo.WriteStatic(quadVertices)
 		quadVbo.MustLayout(0, 2, float32(0), false, 0)
@@ -50,7 +66,7 @@ func NewCanvasWithProgram(prog shader.Program) *Canvas {
 		})
 		return
 	})
-	return &Canvas{Program: prog, quad: *quadVao}
+	return &Canvas{Program: prog, quad: *quadVao, quadVbo: quadVbo}
 }
 
 func (canvas *Canvas) Bind() {
@@ -72,12 +88,78 @@ func (canvas *Canvas) BindFor(context utils.BindingClosure) {
 	}
 }
 
+//Sets the size of the content in pixels, which is used by the ScaleMode
+func (canvas *Canvas) SetContentSize(width, height int) {
+	canvas.content = image.Pt(width, height)
+}
+
+//Sets the size of the framebuffer in pixels. Has to be called whenever the framebuffer is resized.
+func (canvas *Canvas) Resize(width, height int) {
+	canvas.frame = image.Pt(width, height)
+}
+
+//The top-down area of the framebuffer that the content is drawn to
+func (canvas *Canvas) Viewport() image.Rectangle {
+	return canvas.ScaleMode.Viewport(canvas.content, canvas.frame)
+}
+
+//Draws the content. The viewport is only changed if the framebuffer size was set using Resize.
 func (canvas *Canvas) Draw() {
-	gl.Clear(gl.COLOR_BUFFER_BIT)
+	if canvas.frame.X > 0 && canvas.frame.Y > 0 {
+		gl.Viewport(0, 0, int32(canvas.frame.X), int32(canvas.frame.Y))
+	}
+	if canvas.BarColor != nil {
+		//The clear color is global state, so it is restored for other clears
+		var prev [4]float32
+		gl.GetFloatv(gl.COLOR_CLEAR_VALUE, &prev[0])
+		r, g, b, a := canvas.BarColor.RGBA()
+		gl.ClearColor(float32(r)/0xffff, float32(g)/0xffff, float32(b)/0xffff, float32(a)/0xffff)
+		gl.Clear(gl.COLOR_BUFFER_BIT)
+		gl.ClearColor(prev[0], prev[1], prev[2], prev[3])
+	} else {
+		gl.Clear(gl.COLOR_BUFFER_BIT)
+	}
+
+	if canvas.frame.X > 0 && canvas.frame.Y > 0 {
+		vp := canvas.Viewport()
+		size := vp.Size()
+		//OpenGL viewports are bottom-up
+		gl.Viewport(int32(vp.Min.X), int32(canvas.frame.Y-vp.Max.Y), int32(size.X), int32(size.Y))
+	}
 	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
 }
 
+//Reads the default framebuffer. The framebuffer size set by Resize is used, or the viewport if it wasn't set.
+func (canvas *Canvas) Snapshot() (*image.RGBA, error) {
+	size := canvas.frame
+	if size.X <= 0 || size.Y <= 0 {
+		var viewport [4]int32
+		gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+		size = image.Pt(int(viewport[0]+viewport[2]), int(viewport[1]+viewport[3]))
+	}
+
+	img := image.NewRGBA(image.Rectangle{Max: size})
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 4)
+	gl.ReadPixels(0, 0, int32(size.X), int32(size.Y), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	if err := data.CheckGlError("glReadPixels"); err != nil {
+		return nil, err
+	}
+
+	//OpenGL rows are bottom-up
+	row := make([]uint8, img.Stride)
+	for top, bottom := 0, size.Y-1; top < bottom; top, bottom = top+1, bottom-1 {
+		topRow := img.Pix[top*img.Stride : (top+1)*img.Stride]
+		bottomRow := img.Pix[bottom*img.Stride : (bottom+1)*img.Stride]
+		copy(row, topRow)
+		copy(topRow, bottomRow)
+		copy(bottomRow, row)
+	}
+	return img, nil
+}
+
 func (canvas *Canvas) Destroy() {
 	canvas.Program.Destroy()
 	canvas.quad.Destroy()
+	canvas.quadVbo.Destroy()
 }
@@ -1,11 +1,17 @@
 package canvas_test
 
 import (
+	"image"
+	"image/color"
+	"image/draw"
 	"testing"
+	"time"
 
 	"github.com/Qendolin/go-printpixel/internal/canvas"
 	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMain(m *testing.M) {
@@ -29,3 +35,212 @@ func TestCanvasQuad(t *testing.T) {
 		glfw.PollEvents()
 	}
 }
+
+func TestBarColor(t *testing.T) {
+	win, close := test.NewWindow(t)
+	defer close()
+
+	prog := test.NewProgram(t, "assets/shaders/quad_uv.vert", "assets/shaders/quad_uv.frag")
+	cnv := canvas.NewCanvasWithProgram(prog)
+	defer cnv.Destroy()
+	cnv.SetContentSize(1, 1)
+	cnv.ScaleMode = canvas.ScaleFit
+	cnv.BarColor = color.RGBA{255, 0, 0, 255}
+	cnv.Resize(win.GetFramebufferSize())
+
+	gl.ClearColor(0, 0, 1, 1)
+	var img *image.RGBA
+	var err error
+	cnv.BindFor(func() []func() {
+		cnv.Draw()
+		img, err = cnv.Snapshot()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//The window may have the aspect ratio of the content, then there are no bars
+	if cnv.Viewport().Min != (image.Point{}) {
+		assert.Equal(t, color.RGBA{255, 0, 0, 255}, img.RGBAAt(0, 0))
+	}
+
+	//The clear color of the context is restored
+	var clearColor [4]float32
+	gl.GetFloatv(gl.COLOR_CLEAR_VALUE, &clearColor[0])
+	assert.Equal(t, [4]float32{0, 0, 1, 1}, clearColor)
+}
+
+func TestPixelCanvas(t *testing.T) {
+	win, close := test.NewWindow(t)
+	defer close()
+
+	cnv, err := canvas.NewPixelCanvas(64, 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cnv.Destroy()
+
+	draw.Draw(cnv, cnv.Bounds(), &image.Uniform{color.RGBA{0, 0, 255, 255}}, image.Point{}, draw.Src)
+	draw.Draw(cnv, image.Rect(16, 9, 48, 27), &image.Uniform{color.RGBA{0, 255, 0, 255}}, image.Point{}, draw.Src)
+	assert.Equal(t, color.RGBA{0, 255, 0, 255}, cnv.At(32, 18))
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, cnv.At(0, 0))
+
+	for !win.ShouldClose() {
+		cnv.BindFor(func() []func() {
+			cnv.Draw()
+			return nil
+		})
+		win.SwapBuffers()
+		glfw.PollEvents()
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	win, close := test.NewWindow(t)
+	defer close()
+
+	cnv, err := canvas.NewPixelCanvas(2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cnv.Destroy()
+	cnv.Resize(win.GetFramebufferSize())
+
+	//Top row green, bottom row blue
+	cnv.Set(0, 0, color.RGBA{0, 255, 0, 255})
+	cnv.Set(1, 0, color.RGBA{0, 255, 0, 255})
+	cnv.Set(0, 1, color.RGBA{0, 0, 255, 255})
+	cnv.Set(1, 1, color.RGBA{0, 0, 255, 255})
+
+	var img *image.RGBA
+	cnv.BindFor(func() []func() {
+		cnv.Draw()
+		img, err = cnv.Snapshot()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size := img.Bounds().Size()
+	assert.Equal(t, color.RGBA{0, 255, 0, 255}, img.RGBAAt(size.X/2, size.Y/4))
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, img.RGBAAt(size.X/2, size.Y*3/4))
+}
+
+func TestPalettedCanvas(t *testing.T) {
+	win, close := test.NewWindow(t)
+	defer close()
+
+	palette := color.Palette{
+		color.RGBA{0, 0, 0, 255},
+		color.RGBA{255, 0, 0, 255},
+		color.RGBA{0, 255, 0, 255},
+		color.RGBA{0, 0, 255, 255},
+	}
+	cnv, err := canvas.NewPalettedCanvas(3, 1, palette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cnv.Destroy()
+	cnv.Resize(win.GetFramebufferSize())
+
+	cnv.SetColorIndex(0, 0, 1)
+	cnv.SetColorIndex(1, 0, 2)
+	cnv.Set(2, 0, color.RGBA{0, 0, 250, 255})
+	assert.Equal(t, uint8(3), cnv.ColorIndexAt(2, 0))
+
+	snapshot := func() *image.RGBA {
+		var img *image.RGBA
+		cnv.BindFor(func() []func() {
+			cnv.Draw()
+			img, err = cnv.Snapshot()
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+
+	w, h := win.GetFramebufferSize()
+	img := snapshot()
+	assert.Equal(t, palette[1], img.RGBAAt(w/6, h/2))
+	assert.Equal(t, palette[2], img.RGBAAt(w/2, h/2))
+	assert.Equal(t, palette[3], img.RGBAAt(w*5/6, h/2))
+
+	//The indices stay the same, only the colors move
+	cnv.CyclePalette(1, 3, 1)
+	assert.Equal(t, uint8(1), cnv.ColorIndexAt(0, 0))
+	img = snapshot()
+	assert.Equal(t, palette[3], img.RGBAAt(w/6, h/2))
+	assert.Equal(t, palette[1], img.RGBAAt(w/2, h/2))
+	assert.Equal(t, palette[2], img.RGBAAt(w*5/6, h/2))
+
+	cnv.AddCycle(canvas.PaletteCycle{Low: 1, High: 3, Interval: time.Millisecond})
+	for !win.ShouldClose() {
+		cnv.BindFor(func() []func() {
+			cnv.Draw()
+			return nil
+		})
+		win.SwapBuffers()
+		glfw.PollEvents()
+	}
+}
+
+func TestFloatCanvas(t *testing.T) {
+	win, close := test.NewWindow(t)
+	defer close()
+
+	cnv, err := canvas.NewFloatCanvas(3, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cnv.Destroy()
+	cnv.Resize(win.GetFramebufferSize())
+	cnv.Gamma = 1
+	cnv.Set(1, 0, 0.25)
+	cnv.Set(2, 0, 4)
+
+	snapshot := func() *image.RGBA {
+		var img *image.RGBA
+		cnv.BindFor(func() []func() {
+			if err = cnv.Draw(); err == nil {
+				img, err = cnv.Snapshot()
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+
+	w, h := win.GetFramebufferSize()
+	img := snapshot()
+	assert.Equal(t, color.RGBA{0, 0, 0, 255}, img.RGBAAt(w/6, h/2))
+	assert.InDelta(t, 64, img.RGBAAt(w/2, h/2).G, 1)
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, img.RGBAAt(w*5/6, h/2))
+
+	//4 / (1 + 4) = 0.8
+	cnv.ToneMap = canvas.ToneMapReinhard
+	img = snapshot()
+	assert.InDelta(t, 204, img.RGBAAt(w*5/6, h/2).R, 1)
+
+	//2^-1 * 0.25 / (1 + 0.125) = 0.111
+	cnv.Exposure = -1
+	img = snapshot()
+	assert.InDelta(t, 28, img.RGBAAt(w/2, h/2).B, 1)
+
+	_, err = canvas.NewFloatCanvas(1, 1, 5)
+	assert.Equal(t, canvas.ErrChannels, err)
+
+	cnv.ToneMap = canvas.ToneMapAces
+	for !win.ShouldClose() {
+		cnv.BindFor(func() []func() {
+			cnv.Draw()
+			return nil
+		})
+		win.SwapBuffers()
+		glfw.PollEvents()
+	}
+}
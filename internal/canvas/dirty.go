@@ -0,0 +1,55 @@
+package canvas
+
+import "image"
+
+//More rectangles than this are collapsed into a single one
+const maxDirtyRects = 16
+
+//dirtyRegion tracks the modified areas of a pixel buffer as a small set of disjoint rectangles.
+//Rectangles that overlap or touch are merged.
+type dirtyRegion struct {
+	rects []image.Rectangle
+}
+
+func (dr *dirtyRegion) Add(r image.Rectangle) {
+	if r.Empty() {
+		return
+	}
+	for i := 0; i < len(dr.rects); i++ {
+		other := dr.rects[i]
+		if r.In(other) {
+			return
+		}
+		if r.Overlaps(other.Inset(-1)) {
+			r = r.Union(other)
+			dr.rects = append(dr.rects[:i], dr.rects[i+1:]...)
+			//The grown rectangle may touch rectangles that were checked already
+			i = -1
+		}
+	}
+	dr.rects = append(dr.rects, r)
+
+	if len(dr.rects) > maxDirtyRects {
+		dr.rects = []image.Rectangle{dr.Bounds()}
+	}
+}
+
+//The smallest rectangle that contains all dirty rectangles
+func (dr *dirtyRegion) Bounds() (bounds image.Rectangle) {
+	for _, r := range dr.rects {
+		bounds = bounds.Union(r)
+	}
+	return
+}
+
+func (dr *dirtyRegion) Rects() []image.Rectangle {
+	return dr.rects
+}
+
+func (dr *dirtyRegion) Empty() bool {
+	return len(dr.rects) == 0
+}
+
+func (dr *dirtyRegion) Clear() {
+	dr.rects = dr.rects[:0]
+}
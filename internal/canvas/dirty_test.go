@@ -0,0 +1,57 @@
+package canvas
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirtyRegionMerge(t *testing.T) {
+	var dr dirtyRegion
+	assert.True(t, dr.Empty())
+
+	dr.Add(image.Rect(0, 0, 4, 4))
+	dr.Add(image.Rect(1, 1, 2, 2))
+	assert.Equal(t, []image.Rectangle{image.Rect(0, 0, 4, 4)}, dr.Rects(), "contained")
+
+	dr.Add(image.Rect(2, 2, 6, 6))
+	assert.Equal(t, []image.Rectangle{image.Rect(0, 0, 6, 6)}, dr.Rects(), "overlapping")
+
+	dr.Add(image.Rect(6, 0, 8, 2))
+	assert.Equal(t, []image.Rectangle{image.Rect(0, 0, 8, 6)}, dr.Rects(), "adjacent")
+
+	dr.Add(image.Rect(10, 0, 12, 2))
+	assert.Len(t, dr.Rects(), 2, "separated by a gap")
+
+	//Joins both rectangles, which then have to be merged too
+	dr.Add(image.Rect(8, 4, 10, 5))
+	assert.Equal(t, []image.Rectangle{image.Rect(0, 0, 12, 6)}, dr.Rects(), "bridging")
+
+	dr.Clear()
+	assert.True(t, dr.Empty())
+	assert.Equal(t, image.Rectangle{}, dr.Bounds())
+}
+
+func TestDirtyRegionEmptyRect(t *testing.T) {
+	var dr dirtyRegion
+	dr.Add(image.Rectangle{})
+	dr.Add(image.Rect(5, 5, 5, 10))
+	assert.True(t, dr.Empty())
+
+	dr.Add(image.Rect(0, 0, 1, 1))
+	dr.Add(image.Rect(3, 3, 3, 3))
+	assert.Equal(t, []image.Rectangle{image.Rect(0, 0, 1, 1)}, dr.Rects())
+}
+
+func TestDirtyRegionCap(t *testing.T) {
+	var dr dirtyRegion
+	for i := 0; i < maxDirtyRects; i++ {
+		dr.Add(image.Rect(i*4, i*2, i*4+2, i*2+1))
+	}
+	assert.Len(t, dr.Rects(), maxDirtyRects)
+
+	dr.Add(image.Rect(100, 100, 102, 102))
+	assert.Equal(t, []image.Rectangle{image.Rect(0, 0, 102, 102)}, dr.Rects())
+	assert.Equal(t, image.Rect(0, 0, 102, 102), dr.Bounds())
+}
@@ -0,0 +1,187 @@
+package canvas
+
+import (
+	"errors"
+	"image"
+
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/Qendolin/go-printpixel/internal/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+type ToneMap int
+
+//Tone mapping operators, that map unbounded values to [0, 1]
+const (
+	ToneMapClamp = ToneMap(iota)
+	ToneMapReinhard
+	ToneMapAces
+)
+
+var ErrChannels = errors.New("A float canvas has 1 to 4 channels")
+
+var floatFormats = [4]data.PixelFormat{data.FormatR32F, data.FormatRG32F, data.FormatRGB32F, data.FormatRGBA32F}
+
+//A FloatCanvas is a Canvas backed by a CPU side buffer of linear floats, which are tone mapped in the fragment shader.
+//Single channel canvases are displayed as gray.
+type FloatCanvas struct {
+	*Canvas
+	Texture *data.Texture
+	//The values are scaled by 2^Exposure before tone mapping
+	Exposure float32
+	ToneMap  ToneMap
+	//The values are gamma encoded after tone mapping, 1 disables it. The default is 2.2
+	Gamma     float32
+	pix       []float32
+	channels  int
+	rect      image.Rectangle
+	dirty     bool
+	allocated bool
+	uExposure *shader.Uniform
+	uToneMap  *shader.Uniform
+	uGamma    *shader.Uniform
+}
+
+//Creates a canvas using the shaders quad_tex.vert and quad_hdr.frag from assets.Default
+func NewFloatCanvas(width, height, channels int) (*FloatCanvas, error) {
+	if channels < 1 || channels > 4 {
+		return nil, ErrChannels
+	}
+
+	vs, err := shader.NewShaderFromPath("assets/shaders/quad_tex.vert", shader.TypeVertex)
+	if vs != nil {
+		//Also returned if it failed to compile
+		defer vs.Destroy()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fs, err := shader.NewShaderFromPath("assets/shaders/quad_hdr.frag", shader.TypeFragment)
+	if fs != nil {
+		defer fs.Destroy()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prog, err := shader.NewProgram(vs, fs)
+	if err != nil {
+		prog.Destroy()
+		return nil, err
+	}
+
+	uniforms := make([]*shader.Uniform, 3)
+	for i, name := range []string{"u_exposure", "u_tonemap", "u_gamma"} {
+		if uniforms[i], err = shader.NewUniform(*prog, name); err != nil {
+			prog.Destroy()
+			return nil, err
+		}
+	}
+
+	tex := data.NewTexture(data.Texture2D)
+	tex.BindFor(0, func() []func() {
+		tex.FilterMode(data.FilterNearest, data.FilterNearest)
+		tex.WrapMode(data.WrapClampToEdge, data.WrapClampToEdge, 0)
+		if channels == 1 {
+			swizzle := []int32{gl.RED, gl.RED, gl.RED, gl.ONE}
+			gl.TexParameteriv(gl.TEXTURE_2D, gl.TEXTURE_SWIZZLE_RGBA, &swizzle[0])
+		}
+		return nil
+	})
+
+	cnv := NewCanvasWithProgram(*prog)
+	cnv.SetContentSize(width, height)
+	return &FloatCanvas{
+		Canvas:    cnv,
+		Texture:   tex,
+		Gamma:     2.2,
+		pix:       make([]float32, width*height*channels),
+		channels:  channels,
+		rect:      image.Rect(0, 0, width, height),
+		uExposure: uniforms[0],
+		uToneMap:  uniforms[1],
+		uGamma:    uniforms[2],
+	}, nil
+}
+
+func (cnv *FloatCanvas) Bounds() image.Rectangle {
+	return cnv.rect
+}
+
+func (cnv *FloatCanvas) Channels() int {
+	return cnv.channels
+}
+
+//Returns the values of a pixel, the slice is shared with the buffer
+func (cnv *FloatCanvas) At(x, y int) []float32 {
+	if !(image.Point{x, y}.In(cnv.rect)) {
+		return nil
+	}
+	i := (y*cnv.rect.Dx() + x) * cnv.channels
+	return cnv.pix[i : i+cnv.channels]
+}
+
+//Sets the channels of a pixel, missing values are left unchanged
+func (cnv *FloatCanvas) Set(x, y int, values ...float32) {
+	copy(cnv.At(x, y), values)
+	cnv.dirty = true
+}
+
+//Returns the underlying buffer, rows are tightly packed top to bottom.
+//Modifications through it have to be reported using Invalidate.
+func (cnv *FloatCanvas) Pix() []float32 {
+	return cnv.pix
+}
+
+//Marks the buffer as modified, so it is uploaded on the next Upload
+func (cnv *FloatCanvas) Invalidate() {
+	cnv.dirty = true
+}
+
+//Uploads the buffer if it was modified. The texture is left bound to unit 0.
+//The buffer stays modified if the upload fails.
+func (cnv *FloatCanvas) Upload() error {
+	cnv.Texture.Bind(0)
+	if cnv.allocated && !cnv.dirty {
+		return nil
+	}
+	format := floatFormats[cnv.channels-1]
+	size := cnv.rect.Size()
+	if cnv.allocated {
+		if err := cnv.Texture.SubPixels(0, format, 0, 0, 0, size.X, size.Y, 1, 0, cnv.pix); err != nil {
+			return err
+		}
+	} else {
+		if err := cnv.Texture.AllocPixels(0, format, size.X, size.Y, 1, cnv.pix); err != nil {
+			return err
+		}
+		cnv.allocated = true
+	}
+	cnv.dirty = false
+	return nil
+}
+
+//Uploads the buffer if it was modified and draws it. Has to be called while the canvas is bound.
+//Nothing is drawn if the upload or setting the uniforms fails.
+func (cnv *FloatCanvas) Draw() error {
+	if err := cnv.Upload(); err != nil {
+		return err
+	}
+	if err := cnv.uExposure.Set(cnv.Exposure); err != nil {
+		return err
+	}
+	if err := cnv.uToneMap.Set(int32(cnv.ToneMap)); err != nil {
+		return err
+	}
+	if err := cnv.uGamma.Set(cnv.Gamma); err != nil {
+		return err
+	}
+	cnv.Canvas.Draw()
+	return nil
+}
+
+func (cnv *FloatCanvas) Destroy() {
+	cnv.Texture.Destroy()
+	cnv.Canvas.Destroy()
+}
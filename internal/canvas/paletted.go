@@ -0,0 +1,275 @@
+package canvas
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/Qendolin/go-printpixel/internal/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+//The maximum number of palette colors
+const PaletteSize = 256
+
+var ErrPaletteSize = errors.New("A palette can't have more than 256 colors")
+
+//Rotates the colors Low to High (inclusive) of the palette by one step every Interval
+type PaletteCycle struct {
+	Low, High int
+	Interval  time.Duration
+	//Rotates towards lower indices instead
+	Reverse bool
+	elapsed time.Duration
+}
+
+//A PalettedCanvas is a Canvas backed by a CPU side buffer of palette indices, which are resolved in the fragment shader.
+//It implements draw.Image, changing the palette doesn't upload the indices again.
+type PalettedCanvas struct {
+	*Canvas
+	Texture        *data.Texture
+	PaletteTexture *data.Texture
+	pixels         *image.Paletted
+	dirty          dirtyRegion
+	allocated      bool
+	paletteDirty   bool
+	cycles         []PaletteCycle
+	lastCycle      time.Time
+}
+
+//Creates a canvas using the shaders quad_tex.vert and quad_palette.frag from assets.Default
+func NewPalettedCanvas(width, height int, palette color.Palette) (*PalettedCanvas, error) {
+	if len(palette) > PaletteSize {
+		return nil, ErrPaletteSize
+	}
+
+	vs, err := shader.NewShaderFromPath("assets/shaders/quad_tex.vert", shader.TypeVertex)
+	if vs != nil {
+		//Also returned if it failed to compile
+		defer vs.Destroy()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fs, err := shader.NewShaderFromPath("assets/shaders/quad_palette.frag", shader.TypeFragment)
+	if fs != nil {
+		defer fs.Destroy()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prog, err := shader.NewProgram(vs, fs)
+	if err != nil {
+		prog.Destroy()
+		return nil, err
+	}
+
+	uPalette, err := shader.NewUniform(*prog, "u_palette")
+	if err != nil {
+		prog.Destroy()
+		return nil, err
+	}
+	prog.BindFor(func() []func() {
+		err = uPalette.Set(1)
+		return nil
+	})
+	if err != nil {
+		prog.Destroy()
+		return nil, err
+	}
+
+	tex := data.NewTexture(data.Texture2D)
+	tex.BindFor(0, func() []func() {
+		tex.FilterMode(data.FilterNearest, data.FilterNearest)
+		tex.WrapMode(data.WrapClampToEdge, data.WrapClampToEdge, 0)
+		return nil
+	})
+	paletteTex := data.NewTexture(data.Texture1D)
+	paletteTex.BindFor(1, func() []func() {
+		paletteTex.FilterMode(data.FilterNearest, data.FilterNearest)
+		paletteTex.WrapMode(data.WrapClampToEdge, 0, 0)
+		return nil
+	})
+
+	cnv := NewCanvasWithProgram(*prog)
+	cnv.SetContentSize(width, height)
+	return &PalettedCanvas{
+		Canvas:         cnv,
+		Texture:        tex,
+		PaletteTexture: paletteTex,
+		pixels:         image.NewPaletted(image.Rect(0, 0, width, height), append(color.Palette(nil), palette...)),
+		paletteDirty:   true,
+	}, nil
+}
+
+//The palette is the color model
+func (cnv *PalettedCanvas) ColorModel() color.Model {
+	return cnv.pixels.Palette
+}
+
+func (cnv *PalettedCanvas) Bounds() image.Rectangle {
+	return cnv.pixels.Rect
+}
+
+//Indices without a palette color are transparent, like on the gpu
+func (cnv *PalettedCanvas) At(x, y int) color.Color {
+	if index := int(cnv.pixels.ColorIndexAt(x, y)); index >= len(cnv.pixels.Palette) {
+		return color.Transparent
+	}
+	return cnv.pixels.At(x, y)
+}
+
+//Sets the pixel to the palette index of the closest color
+func (cnv *PalettedCanvas) Set(x, y int, c color.Color) {
+	cnv.pixels.Set(x, y, c)
+	cnv.Invalidate(image.Rect(x, y, x+1, y+1))
+}
+
+func (cnv *PalettedCanvas) ColorIndexAt(x, y int) uint8 {
+	return cnv.pixels.ColorIndexAt(x, y)
+}
+
+func (cnv *PalettedCanvas) SetColorIndex(x, y int, index uint8) {
+	cnv.pixels.SetColorIndex(x, y, index)
+	cnv.Invalidate(image.Rect(x, y, x+1, y+1))
+}
+
+//Returns the underlying index buffer.
+//Regions that are modified through it have to be reported using Invalidate, palette changes using SetPalette.
+func (cnv *PalettedCanvas) Image() *image.Paletted {
+	return cnv.pixels
+}
+
+//Marks a region of the index buffer as modified, so it is uploaded on the next Upload
+func (cnv *PalettedCanvas) Invalidate(rect image.Rectangle) {
+	cnv.dirty.Add(rect.Intersect(cnv.pixels.Rect))
+}
+
+func (cnv *PalettedCanvas) Palette() color.Palette {
+	return cnv.pixels.Palette
+}
+
+//Replaces the palette without changing the indices
+func (cnv *PalettedCanvas) SetPalette(palette color.Palette) error {
+	if len(palette) > PaletteSize {
+		return ErrPaletteSize
+	}
+	cnv.pixels.Palette = append(cnv.pixels.Palette[:0], palette...)
+	cnv.paletteDirty = true
+	return nil
+}
+
+//Rotates the colors low to high (inclusive) by steps towards higher indices, negative steps rotate towards lower indices
+func (cnv *PalettedCanvas) CyclePalette(low, high, steps int) {
+	if low < 0 {
+		low = 0
+	}
+	if high >= len(cnv.pixels.Palette) {
+		high = len(cnv.pixels.Palette) - 1
+	}
+	length := high - low + 1
+	if length < 2 {
+		return
+	}
+	steps %= length
+	if steps < 0 {
+		steps += length
+	}
+	if steps == 0 {
+		return
+	}
+
+	colors := cnv.pixels.Palette[low : high+1]
+	rotated := append(append(color.Palette(nil), colors[length-steps:]...), colors[:length-steps]...)
+	copy(colors, rotated)
+	cnv.paletteDirty = true
+}
+
+//Adds a palette cycle that is advanced by the time that passed between draws
+func (cnv *PalettedCanvas) AddCycle(cycle PaletteCycle) {
+	cnv.cycles = append(cnv.cycles, cycle)
+}
+
+func (cnv *PalettedCanvas) ClearCycles() {
+	cnv.cycles = nil
+}
+
+//Advances the palette cycles by dt
+func (cnv *PalettedCanvas) Update(dt time.Duration) {
+	for i := range cnv.cycles {
+		cycle := &cnv.cycles[i]
+		if cycle.Interval <= 0 {
+			continue
+		}
+		cycle.elapsed += dt
+		steps := int(cycle.elapsed / cycle.Interval)
+		cycle.elapsed %= cycle.Interval
+		if cycle.Reverse {
+			steps = -steps
+		}
+		cnv.CyclePalette(cycle.Low, cycle.High, steps)
+	}
+}
+
+//Uploads the palette if it was changed and the modified regions of the index buffer.
+//The index texture is left bound to unit 0 and the palette texture to unit 1.
+func (cnv *PalettedCanvas) Upload() {
+	if cnv.paletteDirty {
+		palette := make([]uint8, PaletteSize*4)
+		for i, c := range cnv.pixels.Palette {
+			rgba := color.RGBAModel.Convert(c).(color.RGBA)
+			copy(palette[i*4:], []uint8{rgba.R, rgba.G, rgba.B, rgba.A})
+		}
+		cnv.PaletteTexture.Bind(1)
+		cnv.PaletteTexture.Alloc(0, gl.RGBA8, PaletteSize, 0, 0, gl.RGBA, gl.UNSIGNED_BYTE, palette)
+		cnv.paletteDirty = false
+	} else {
+		cnv.PaletteTexture.Bind(1)
+	}
+
+	cnv.Texture.Bind(0)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	defer gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	if !cnv.allocated {
+		size := cnv.pixels.Rect.Size()
+		cnv.Texture.Alloc(0, gl.R8, int32(size.X), int32(size.Y), 0, gl.RED, gl.UNSIGNED_BYTE, cnv.pixels.Pix)
+		cnv.allocated = true
+		cnv.dirty.Clear()
+		return
+	}
+	if cnv.dirty.Empty() {
+		return
+	}
+
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, int32(cnv.pixels.Stride))
+	for _, rect := range cnv.dirty.Rects() {
+		size := rect.Size()
+		offset := cnv.pixels.PixOffset(rect.Min.X, rect.Min.Y)
+		x, y := rect.Min.X-cnv.pixels.Rect.Min.X, rect.Min.Y-cnv.pixels.Rect.Min.Y
+		cnv.Texture.SubImage(0, int32(x), int32(y), 0, int32(size.X), int32(size.Y), 0, gl.RED, gl.UNSIGNED_BYTE, cnv.pixels.Pix[offset:])
+	}
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+	cnv.dirty.Clear()
+}
+
+//Advances the palette cycles, uploads the changes and draws the canvas. Has to be called while the canvas is bound.
+func (cnv *PalettedCanvas) Draw() {
+	now := time.Now()
+	if !cnv.lastCycle.IsZero() {
+		cnv.Update(now.Sub(cnv.lastCycle))
+	}
+	cnv.lastCycle = now
+
+	cnv.Upload()
+	cnv.Canvas.Draw()
+}
+
+func (cnv *PalettedCanvas) Destroy() {
+	cnv.Texture.Destroy()
+	cnv.PaletteTexture.Destroy()
+	cnv.Canvas.Destroy()
+}
@@ -0,0 +1,150 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/Qendolin/go-printpixel/internal/shader"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+//A PixelCanvas is a Canvas backed by a CPU side RGBA pixel buffer.
+//It implements draw.Image, the modified regions of the buffer are uploaded to its texture when it is drawn.
+type PixelCanvas struct {
+	*Canvas
+	Texture   *data.Texture
+	pixels    *image.RGBA
+	dirty     dirtyRegion
+	allocated bool
+	stream    *data.Stream
+	//Set if the stream could not be created, full frames are then uploaded with SubImage
+	streamErr error
+}
+
+func NewPixelCanvas(width, height int) (*PixelCanvas, error) {
+	cnv, err := NewCanvas()
+	if err != nil {
+		return nil, err
+	}
+	return newPixelCanvas(cnv, width, height), nil
+}
+
+func NewPixelCanvasWithProgram(prog shader.Program, width, height int) *PixelCanvas {
+	return newPixelCanvas(NewCanvasWithProgram(prog), width, height)
+}
+
+func newPixelCanvas(cnv *Canvas, width, height int) *PixelCanvas {
+	tex := data.NewTexture(data.Texture2D)
+	tex.BindFor(0, func() []func() {
+		tex.FilterMode(data.FilterNearest, data.FilterNearest)
+		tex.WrapMode(data.WrapClampToEdge, data.WrapClampToEdge, 0)
+		return nil
+	})
+	cnv.SetContentSize(width, height)
+	return &PixelCanvas{
+		Canvas:  cnv,
+		Texture: tex,
+		pixels:  image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+}
+
+func (cnv *PixelCanvas) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (cnv *PixelCanvas) Bounds() image.Rectangle {
+	return cnv.pixels.Rect
+}
+
+func (cnv *PixelCanvas) At(x, y int) color.Color {
+	return cnv.pixels.At(x, y)
+}
+
+func (cnv *PixelCanvas) Set(x, y int, c color.Color) {
+	cnv.pixels.Set(x, y, c)
+	cnv.Invalidate(image.Rect(x, y, x+1, y+1))
+}
+
+func (cnv *PixelCanvas) SetRGBA(x, y int, c color.RGBA) {
+	cnv.pixels.SetRGBA(x, y, c)
+	cnv.Invalidate(image.Rect(x, y, x+1, y+1))
+}
+
+//Returns the underlying pixel buffer.
+//Regions that are modified through it have to be reported using Invalidate.
+func (cnv *PixelCanvas) Image() *image.RGBA {
+	return cnv.pixels
+}
+
+//Marks a region of the pixel buffer as modified, so it is uploaded on the next Upload
+func (cnv *PixelCanvas) Invalidate(rect image.Rectangle) {
+	cnv.dirty.Add(rect.Intersect(cnv.pixels.Rect))
+}
+
+//Uploads the modified regions of the pixel buffer to the texture. The texture is left bound to unit 0.
+func (cnv *PixelCanvas) Upload() {
+	cnv.Texture.Bind(0)
+	if !cnv.allocated {
+		size := cnv.pixels.Rect.Size()
+		cnv.Texture.Alloc(0, gl.RGBA8, int32(size.X), int32(size.Y), 0, gl.RGBA, gl.UNSIGNED_BYTE, cnv.pixels.Pix)
+		cnv.allocated = true
+		cnv.dirty.Clear()
+		return
+	}
+	if cnv.dirty.Empty() {
+		return
+	}
+
+	rects := cnv.dirty.Rects()
+	if len(rects) == 1 && rects[0] == cnv.pixels.Rect && cnv.streamFrame() {
+		cnv.dirty.Clear()
+		return
+	}
+
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, int32(cnv.pixels.Stride/4))
+	for _, rect := range rects {
+		size := rect.Size()
+		offset := cnv.pixels.PixOffset(rect.Min.X, rect.Min.Y)
+		x, y := rect.Min.X-cnv.pixels.Rect.Min.X, rect.Min.Y-cnv.pixels.Rect.Min.Y
+		cnv.Texture.SubImage(0, int32(x), int32(y), 0, int32(size.X), int32(size.Y), 0, gl.RGBA, gl.UNSIGNED_BYTE, cnv.pixels.Pix[offset:])
+	}
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+	cnv.dirty.Clear()
+}
+
+//Uploads the whole pixel buffer through a stream, so full frame updates don't stall
+func (cnv *PixelCanvas) streamFrame() bool {
+	if cnv.streamErr != nil {
+		return false
+	}
+	if cnv.stream == nil {
+		size := cnv.pixels.Rect.Size()
+		stream, err := data.NewStream(cnv.Texture, int32(size.X), int32(size.Y), data.FormatRGBA8, 3)
+		if err != nil {
+			cnv.streamErr = err
+			return false
+		}
+		cnv.stream = stream
+	}
+	return cnv.stream.Write(cnv.pixels.Pix) == nil
+}
+
+//The error that prevented streaming full frames, they are then uploaded directly. Nil while streaming works.
+func (cnv *PixelCanvas) StreamErr() error {
+	return cnv.streamErr
+}
+
+//Uploads the modified regions of the pixel buffer and draws it. Has to be called while the canvas is bound.
+func (cnv *PixelCanvas) Draw() {
+	cnv.Upload()
+	cnv.Canvas.Draw()
+}
+
+func (cnv *PixelCanvas) Destroy() {
+	if cnv.stream != nil {
+		cnv.stream.Destroy()
+	}
+	cnv.Texture.Destroy()
+	cnv.Canvas.Destroy()
+}
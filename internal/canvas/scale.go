@@ -0,0 +1,58 @@
+package canvas
+
+import (
+	"image"
+	"math"
+)
+
+type ScaleMode int
+
+const (
+	//Stretches the content over the whole framebuffer
+	ScaleStretch = ScaleMode(iota)
+	//Scales the content to fit into the framebuffer while keeping its aspect ratio, leaving bars on two sides
+	ScaleFit
+	//Scales the content to cover the whole framebuffer while keeping its aspect ratio, cropping two sides
+	ScaleFill
+	//Like ScaleFit but only scales by whole numbers. Content larger than the framebuffer is scaled like ScaleFit.
+	ScaleInteger
+	//Centers the content without scaling it
+	ScaleCenter
+)
+
+/*
+	Computes the area the content covers in a framebuffer.
+	The rectangle is top-down and may exceed the framebuffer.
+
+	content - the size of the content in pixels
+	frame - the size of the framebuffer in pixels
+*/
+func (mode ScaleMode) Viewport(content, frame image.Point) image.Rectangle {
+	full := image.Rectangle{Max: frame}
+	if content.X <= 0 || content.Y <= 0 || frame.X <= 0 || frame.Y <= 0 {
+		return full
+	}
+
+	scaleX := float64(frame.X) / float64(content.X)
+	scaleY := float64(frame.Y) / float64(content.Y)
+	var scale float64
+	switch mode {
+	case ScaleFit:
+		scale = math.Min(scaleX, scaleY)
+	case ScaleFill:
+		scale = math.Max(scaleX, scaleY)
+	case ScaleInteger:
+		scale = math.Min(scaleX, scaleY)
+		if scale >= 1 {
+			scale = math.Floor(scale)
+		}
+	case ScaleCenter:
+		scale = 1
+	default:
+		return full
+	}
+
+	size := image.Pt(int(math.Round(float64(content.X)*scale)), int(math.Round(float64(content.Y)*scale)))
+	min := frame.Sub(size).Div(2)
+	return image.Rectangle{Min: min, Max: min.Add(size)}
+}
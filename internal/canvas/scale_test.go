@@ -0,0 +1,31 @@
+package canvas_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/canvas"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaleModeViewport(t *testing.T) {
+	cases := []struct {
+		mode    canvas.ScaleMode
+		content image.Point
+		frame   image.Point
+		want    image.Rectangle
+	}{
+		{canvas.ScaleStretch, image.Pt(100, 100), image.Pt(800, 450), image.Rect(0, 0, 800, 450)},
+		{canvas.ScaleFit, image.Pt(100, 100), image.Pt(800, 450), image.Rect(175, 0, 625, 450)},
+		{canvas.ScaleFit, image.Pt(160, 90), image.Pt(800, 800), image.Rect(0, 175, 800, 625)},
+		{canvas.ScaleFill, image.Pt(100, 100), image.Pt(800, 450), image.Rect(0, -175, 800, 625)},
+		{canvas.ScaleInteger, image.Pt(100, 100), image.Pt(800, 450), image.Rect(200, 25, 600, 425)},
+		{canvas.ScaleInteger, image.Pt(1000, 1000), image.Pt(800, 400), image.Rect(200, 0, 600, 400)},
+		{canvas.ScaleCenter, image.Pt(100, 50), image.Pt(800, 450), image.Rect(350, 200, 450, 250)},
+		{canvas.ScaleFit, image.Pt(0, 0), image.Pt(800, 450), image.Rect(0, 0, 800, 450)},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, c.mode.Viewport(c.content, c.frame), "mode %v, content %v, frame %v", c.mode, c.content, c.frame)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 
+	"github.com/Qendolin/go-printpixel/internal/tracker"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -51,6 +52,9 @@ func InitGl(cfg glConfig) (err error) {
 		return
 	}
 
+	status |= StatusGlInitialized
+	//The context may be a different one
+	extensions = nil
 	return
 }
 
@@ -64,13 +68,34 @@ func InitGlfw() (err error) {
 	return err
 }
 
+//Terminates GLFW. If the tracker is enabled, the objects that were not deleted are logged and forgotten.
 func Terminate() {
+	if tracker.Enabled() {
+		reportLeaks()
+	}
 	if status&StatusGlfwInitialized > 0 {
 		glfw.Terminate()
-		status &= ^StatusGlfwInitialized
+		status &= ^(StatusGlfwInitialized | StatusGlInitialized)
+		extensions = nil
 	}
 }
 
 func Status() int {
 	return status
 }
+
+func reportLeaks() {
+	leaks := tracker.Leaks()
+	if len(leaks) == 0 {
+		return
+	}
+	size := 0
+	for _, leak := range leaks {
+		size += leak.Size
+	}
+	log.Printf("%v OpenGL objects (%v bytes) were not deleted:\n", len(leaks), size)
+	for _, leak := range leaks {
+		log.Println(leak)
+	}
+	tracker.Reset()
+}
@@ -97,3 +97,22 @@ func TestGlInit(t *testing.T) {
 	assert.NoError(t, err)
 	gl.GetString(gl.VERSION)
 }
+
+func TestExtensionSupported(t *testing.T) {
+	err := context.InitGlfw()
+	assert.NoError(t, err)
+	defer context.Terminate()
+
+	assert.False(t, context.ExtensionSupported("GL_ARB_debug_output"))
+
+	hints := window.NewHints()
+	hints.Visible.Value = false
+	win, err := window.New(hints, "Test Window", 800, 450, nil)
+	defer win.Destroy()
+	assert.NoError(t, err)
+	win.MakeContextCurrent()
+	err = context.InitGl(context.NewGlConfig(0))
+	assert.NoError(t, err)
+	assert.Equal(t, int(context.StatusGlfwInitialized|context.StatusGlInitialized), context.Status())
+	assert.False(t, context.ExtensionSupported("GL_not_an_extension"))
+}
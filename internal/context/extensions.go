@@ -0,0 +1,24 @@
+package context
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+//The extensions of the current context, queried on first use
+var extensions map[string]bool
+
+//Reports whether the current context supports an extension, e.g. "GL_EXT_texture_filter_anisotropic"
+func ExtensionSupported(name string) bool {
+	if extensions == nil {
+		if status&StatusGlInitialized == 0 {
+			return false
+		}
+		var count int32
+		gl.GetIntegerv(gl.NUM_EXTENSIONS, &count)
+		extensions = make(map[string]bool, count)
+		for i := uint32(0); i < uint32(count); i++ {
+			extensions[gl.GoStr(gl.GetStringi(gl.EXTENSIONS, i))] = true
+		}
+	}
+	return extensions[name]
+}
@@ -21,9 +21,11 @@ func (glerr openGlError) Error() string {
 
 type glConfig struct {
 	//Enables DEBUG_OUTPUT and DEBUG_OUTPUT_SYNCHRONOUS. Also sets DebugMessageCallback.
-	Debug  bool
-	Errors <-chan openGlError
-	errors chan<- openGlError
+	Debug bool
+	//Enables TEXTURE_CUBE_MAP_SEAMLESS, so cube maps are filtered across face edges
+	SeamlessCubeMap bool
+	Errors          <-chan openGlError
+	errors          chan<- openGlError
 }
 
 func NewGlConfig(errorChanBufferSize int) glConfig {
@@ -41,6 +43,9 @@ func (cfg glConfig) Apply() error {
 		gl.Enable(gl.DEBUG_OUTPUT)
 		gl.Enable(gl.DEBUG_OUTPUT_SYNCHRONOUS)
 	}
+	if cfg.SeamlessCubeMap {
+		gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+	}
 	return nil
 }
 
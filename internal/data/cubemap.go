@@ -0,0 +1,295 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+type CubeFace int
+
+//Cube map faces, in the order of their texture targets
+const (
+	FacePositiveX = CubeFace(iota)
+	FaceNegativeX
+	FacePositiveY
+	FaceNegativeY
+	FacePositiveZ
+	FaceNegativeZ
+)
+
+var ErrCubeFaceSize = errors.New("The cube map faces have to be square and of the same size")
+
+type CrossLayoutErr struct {
+	Size image.Point
+}
+
+func (clerr CrossLayoutErr) Error() string {
+	return fmt.Sprintf("Image size %v is neither a horizontal 4x3 nor a vertical 3x4 cross", clerr.Size)
+}
+
+//The texture target of the face
+func (face CubeFace) Target() TexTarget {
+	return TexTarget(int(TextureCubeMapPositiveX) + int(face))
+}
+
+type CubeMap struct {
+	*Texture
+}
+
+func NewCubeMap() *CubeMap {
+	return &CubeMap{NewTexture(TextureCubeMap)}
+}
+
+//The texture of a single face, used to allocate or update it
+func (cube *CubeMap) Face(face CubeFace) *Texture {
+	return cube.As(face.Target())
+}
+
+/*
+	Uploads six square images of the same size. The cube map has to be bound.
+
+	faces - +x, -x, +y, -y, +z, -z
+*/
+func (cube *CubeMap) AllocFaces(faces [6]image.Image, level int32) error {
+	size := faces[0].Bounds().Size()
+	for _, face := range faces {
+		if s := face.Bounds().Size(); s.X != s.Y || s != size {
+			return ErrCubeFaceSize
+		}
+	}
+
+	for i, face := range faces {
+		cube.Face(CubeFace(i)).AllocWithImage(face, level)
+	}
+	return nil
+}
+
+//Uploads an image in a horizontal or vertical cross layout, see CrossFaces. The cube map has to be bound.
+func (cube *CubeMap) AllocWithCross(img image.Image, level int32) error {
+	faces, err := CrossFaces(img)
+	if err != nil {
+		return err
+	}
+	return cube.AllocFaces(faces, level)
+}
+
+/*
+	Converts an equirectangular panorama to faces and uploads them. The cube map has to be bound.
+
+	faceSize - the width and height of each face
+*/
+func (cube *CubeMap) AllocWithEquirectangular(img image.Image, faceSize int, level int32) error {
+	faces := EquirectToFaces(img, faceSize)
+	var images [6]image.Image
+	for i, face := range faces {
+		images[i] = face
+	}
+	return cube.AllocFaces(images, level)
+}
+
+//Enables or disables filtering across cube map face edges. This is global state of the context.
+func SetSeamlessCubeMap(enabled bool) {
+	if enabled {
+		gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+	} else {
+		gl.Disable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+	}
+}
+
+//Face positions in a cross layout in face size units, the vertical cross stores -z upside down
+var (
+	horizontalCross = [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {3, 1}}
+	verticalCross   = [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {1, 3}}
+)
+
+/*
+	Splits an image in a cross layout into the faces +x, -x, +y, -y, +z, -z.
+	The horizontal cross is 4x3 faces with -x, +z, +x, -z in the middle row.
+	The vertical cross is 3x4 faces with -x, +z, +x in the second row and -z, upside down, at the bottom.
+*/
+func CrossFaces(img image.Image) (faces [6]image.Image, err error) {
+	bounds := img.Bounds()
+	size := bounds.Size()
+
+	var layout [6]image.Point
+	var faceSize int
+	switch {
+	case size.X%4 == 0 && size.X*3 == size.Y*4:
+		layout = horizontalCross
+		faceSize = size.X / 4
+	case size.X%3 == 0 && size.X*4 == size.Y*3:
+		layout = verticalCross
+		faceSize = size.X / 3
+	default:
+		return faces, CrossLayoutErr{Size: size}
+	}
+
+	for i, pos := range layout {
+		min := bounds.Min.Add(pos.Mul(faceSize))
+		faces[i] = subImage(img, image.Rectangle{Min: min, Max: min.Add(image.Pt(faceSize, faceSize))})
+	}
+	if layout == verticalCross {
+		faces[FaceNegativeZ] = rotate180(faces[FaceNegativeZ])
+	}
+	return faces, nil
+}
+
+func subImage(img image.Image, rect image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	rgba := image.NewRGBA(image.Rectangle{Max: rect.Size()})
+	draw.Draw(rgba, rgba.Rect, img, rect.Min, draw.Src)
+	return rgba
+}
+
+//Rotates an image by 180 degrees, keeping its type if it stores its pixels in a byte slice
+func rotate180(img image.Image) image.Image {
+	bounds := img.Bounds()
+	rect := image.Rectangle{Max: bounds.Size()}
+	var dst image.Image
+	var dstPix, srcPix []uint8
+	var dstStride, srcStride, pixelSize int
+
+	switch src := img.(type) {
+	case *image.RGBA:
+		rgba := image.NewRGBA(rect)
+		dst, dstPix, dstStride = rgba, rgba.Pix, rgba.Stride
+		srcPix, srcStride, pixelSize = src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride, 4
+	case *image.NRGBA:
+		nrgba := image.NewNRGBA(rect)
+		dst, dstPix, dstStride = nrgba, nrgba.Pix, nrgba.Stride
+		srcPix, srcStride, pixelSize = src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride, 4
+	case *image.RGBA64:
+		rgba := image.NewRGBA64(rect)
+		dst, dstPix, dstStride = rgba, rgba.Pix, rgba.Stride
+		srcPix, srcStride, pixelSize = src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride, 8
+	case *image.NRGBA64:
+		nrgba := image.NewNRGBA64(rect)
+		dst, dstPix, dstStride = nrgba, nrgba.Pix, nrgba.Stride
+		srcPix, srcStride, pixelSize = src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride, 8
+	case *image.Gray:
+		gray := image.NewGray(rect)
+		dst, dstPix, dstStride = gray, gray.Pix, gray.Stride
+		srcPix, srcStride, pixelSize = src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride, 1
+	case *image.Gray16:
+		gray := image.NewGray16(rect)
+		dst, dstPix, dstStride = gray, gray.Pix, gray.Stride
+		srcPix, srcStride, pixelSize = src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride, 2
+	case *image.Paletted:
+		paletted := image.NewPaletted(rect, src.Palette)
+		dst, dstPix, dstStride = paletted, paletted.Pix, paletted.Stride
+		srcPix, srcStride, pixelSize = src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride, 1
+	default:
+		//Other images are converted to RGBA on upload anyway
+		rgba := image.NewRGBA(rect)
+		for y := 0; y < rect.Max.Y; y++ {
+			for x := 0; x < rect.Max.X; x++ {
+				rgba.Set(rect.Max.X-1-x, rect.Max.Y-1-y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			}
+		}
+		return rgba
+	}
+
+	w, h := rect.Max.X, rect.Max.Y
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := (h-1-y)*dstStride + (w-1-x)*pixelSize
+			s := y*srcStride + x*pixelSize
+			copy(dstPix[d:d+pixelSize], srcPix[s:s+pixelSize])
+		}
+	}
+	return dst
+}
+
+//Returns the direction of a face pixel, u and v are in [-1, 1] and point right and down
+func faceDirection(face CubeFace, u, v float64) (x, y, z float64) {
+	switch face {
+	case FacePositiveX:
+		return 1, -v, -u
+	case FaceNegativeX:
+		return -1, -v, u
+	case FacePositiveY:
+		return u, 1, v
+	case FaceNegativeY:
+		return u, -1, -v
+	case FacePositiveZ:
+		return u, -v, 1
+	default:
+		return -u, -v, -1
+	}
+}
+
+/*
+	Converts an equirectangular panorama to the faces +x, -x, +y, -y, +z, -z using bilinear filtering.
+	The center of the panorama faces -z, its left and right edges +z.
+
+	size - the width and height of each face
+*/
+func EquirectToFaces(img image.Image, size int) (faces [6]*image.RGBA) {
+	for i := range faces {
+		face := image.NewRGBA(image.Rect(0, 0, size, size))
+		for py := 0; py < size; py++ {
+			v := 2*(float64(py)+0.5)/float64(size) - 1
+			for px := 0; px < size; px++ {
+				u := 2*(float64(px)+0.5)/float64(size) - 1
+				x, y, z := faceDirection(CubeFace(i), u, v)
+				lon := math.Atan2(x, -z)
+				lat := math.Atan2(y, math.Hypot(x, z))
+				face.SetRGBA(px, py, sampleBilinear(img, lon/(2*math.Pi)+0.5, 0.5-lat/math.Pi))
+			}
+		}
+		faces[i] = face
+	}
+	return faces
+}
+
+//Samples img at normalized coordinates, wrapping horizontally and clamping vertically
+func sampleBilinear(img image.Image, s, t float64) color.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	fx := s*float64(w) - 0.5
+	fy := t*float64(h) - 0.5
+	x0, y0 := int(math.Floor(fx)), int(math.Floor(fy))
+	ax, ay := fx-float64(x0), fy-float64(y0)
+
+	wrapX := func(x int) int {
+		return bounds.Min.X + ((x%w)+w)%w
+	}
+	clampY := func(y int) int {
+		if y < 0 {
+			y = 0
+		} else if y >= h {
+			y = h - 1
+		}
+		return bounds.Min.Y + y
+	}
+
+	var sum [4]float64
+	sample := func(x, y int, weight float64) {
+		r, g, b, a := img.At(wrapX(x), clampY(y)).RGBA()
+		sum[0] += float64(r) * weight
+		sum[1] += float64(g) * weight
+		sum[2] += float64(b) * weight
+		sum[3] += float64(a) * weight
+	}
+	sample(x0, y0, (1-ax)*(1-ay))
+	sample(x0+1, y0, ax*(1-ay))
+	sample(x0, y0+1, (1-ax)*ay)
+	sample(x0+1, y0+1, ax*ay)
+
+	return color.RGBA{
+		R: uint8(sum[0]/257 + 0.5),
+		G: uint8(sum[1]/257 + 0.5),
+		B: uint8(sum[2]/257 + 0.5),
+		A: uint8(sum[3]/257 + 0.5),
+	}
+}
@@ -0,0 +1,98 @@
+package data_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+//Fills every face of a cross with a distinct gray value
+func crossImage(size image.Point, faceSize int, positions [6]image.Point) *image.Gray {
+	img := image.NewGray(image.Rectangle{Max: size})
+	for i, pos := range positions {
+		for y := 0; y < faceSize; y++ {
+			for x := 0; x < faceSize; x++ {
+				img.SetGray(pos.X*faceSize+x, pos.Y*faceSize+y, color.Gray{uint8(10 * (i + 1))})
+			}
+		}
+	}
+	return img
+}
+
+func TestCrossFaces(t *testing.T) {
+	horizontal := crossImage(image.Pt(8, 6), 2, [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {3, 1}})
+	faces, err := data.CrossFaces(horizontal)
+	assert.NoError(t, err)
+	for i, face := range faces {
+		assert.Equal(t, image.Pt(2, 2), face.Bounds().Size())
+		assert.Equal(t, color.Gray{uint8(10 * (i + 1))}, face.At(face.Bounds().Min.X, face.Bounds().Min.Y), "face %v", i)
+	}
+
+	vertical := crossImage(image.Pt(6, 8), 2, [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {1, 3}})
+	//Mark the bottom right pixel of -z, it is the top left one after rotating
+	vertical.SetGray(3, 7, color.Gray{255})
+	faces, err = data.CrossFaces(vertical)
+	assert.NoError(t, err)
+	for _, face := range faces {
+		assert.Equal(t, image.Pt(2, 2), face.Bounds().Size())
+	}
+	negZ := faces[data.FaceNegativeZ]
+	assert.IsType(t, &image.Gray{}, negZ)
+	assert.Equal(t, color.Gray{255}, negZ.At(0, 0))
+	assert.Equal(t, color.Gray{60}, negZ.At(1, 1))
+
+	_, err = data.CrossFaces(image.NewGray(image.Rect(0, 0, 5, 5)))
+	assert.Equal(t, data.CrossLayoutErr{Size: image.Pt(5, 5)}, err)
+}
+
+func TestEquirectToFaces(t *testing.T) {
+	//The top half is red and the bottom half blue
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			if y < 16 {
+				img.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{0, 0, 255, 255})
+			}
+		}
+	}
+
+	faces := data.EquirectToFaces(img, 8)
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, faces[data.FacePositiveY].RGBAAt(4, 4))
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, faces[data.FaceNegativeY].RGBAAt(4, 4))
+	for _, face := range []data.CubeFace{data.FacePositiveX, data.FaceNegativeX, data.FacePositiveZ, data.FaceNegativeZ} {
+		assert.Equal(t, color.RGBA{255, 0, 0, 255}, faces[face].RGBAAt(4, 0), "face %v", face)
+		assert.Equal(t, color.RGBA{0, 0, 255, 255}, faces[face].RGBAAt(4, 7), "face %v", face)
+	}
+}
+
+func TestCubeMapTexture(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	data.SetSeamlessCubeMap(true)
+	defer data.SetSeamlessCubeMap(false)
+
+	cube := data.NewCubeMap()
+	defer cube.Destroy()
+	cube.Bind(0)
+	cube.FilterMode(data.FilterLinear, data.FilterLinear)
+	cross := crossImage(image.Pt(32, 24), 8, [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {3, 1}})
+	assert.NoError(t, cube.AllocWithCross(cross, 0))
+	assert.NoError(t, data.CheckGlError("cube map upload"))
+
+	img, err := cube.Face(data.FaceNegativeZ).ReadImage(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Swizzling doesn't apply to reading the texture
+	assert.Equal(t, color.RGBA{60, 0, 0, 255}, img.RGBAAt(4, 4))
+
+	err = cube.AllocFaces([6]image.Image{cross, cross, cross, cross, cross, cross}, 0)
+	assert.Equal(t, data.ErrCubeFaceSize, err)
+}
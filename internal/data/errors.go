@@ -0,0 +1,24 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+type GlErr struct {
+	Code uint32
+	Op   string
+}
+
+func (glerr GlErr) Error() string {
+	return fmt.Sprintf("%v failed with OpenGL error 0x%04X", glerr.Op, glerr.Code)
+}
+
+//Returns a GlErr if the OpenGL error flag is set
+func CheckGlError(op string) error {
+	if code := gl.GetError(); code != gl.NO_ERROR {
+		return GlErr{Code: code, Op: op}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package data
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+type ComponentType int
+
+const (
+	ComponentUint8 = ComponentType(iota)
+	ComponentInt8
+	ComponentUint16
+	ComponentInt16
+	ComponentUint32
+	ComponentInt32
+	ComponentFloat16
+	ComponentFloat32
+)
+
+//Describes the layout of pixel data in client memory and the texture format it is stored as
+type PixelFormat struct {
+	//1 to 4, the channels are red, green, blue and alpha
+	Channels int
+	Type     ComponentType
+	//Integer components are sampled as floats in [0, 1] or [-1, 1] instead of as integers. Ignored for float components.
+	Normalized bool
+	//The color channels are sRGB encoded. Only valid with 3 or 4 normalized uint8 components.
+	SRGB bool
+}
+
+//Common pixel formats
+var (
+	FormatR8      = PixelFormat{Channels: 1, Type: ComponentUint8, Normalized: true}
+	FormatRG8     = PixelFormat{Channels: 2, Type: ComponentUint8, Normalized: true}
+	FormatRGB8    = PixelFormat{Channels: 3, Type: ComponentUint8, Normalized: true}
+	FormatRGBA8   = PixelFormat{Channels: 4, Type: ComponentUint8, Normalized: true}
+	FormatSRGB8   = PixelFormat{Channels: 3, Type: ComponentUint8, Normalized: true, SRGB: true}
+	FormatSRGBA8  = PixelFormat{Channels: 4, Type: ComponentUint8, Normalized: true, SRGB: true}
+	FormatR16     = PixelFormat{Channels: 1, Type: ComponentUint16, Normalized: true}
+	FormatRGBA16  = PixelFormat{Channels: 4, Type: ComponentUint16, Normalized: true}
+	FormatR16F    = PixelFormat{Channels: 1, Type: ComponentFloat16}
+	FormatRGBA16F = PixelFormat{Channels: 4, Type: ComponentFloat16}
+	FormatR32F    = PixelFormat{Channels: 1, Type: ComponentFloat32}
+	FormatRG32F   = PixelFormat{Channels: 2, Type: ComponentFloat32}
+	FormatRGB32F  = PixelFormat{Channels: 3, Type: ComponentFloat32}
+	FormatRGBA32F = PixelFormat{Channels: 4, Type: ComponentFloat32}
+	FormatR8UI    = PixelFormat{Channels: 1, Type: ComponentUint8}
+	FormatR32UI   = PixelFormat{Channels: 1, Type: ComponentUint32}
+	FormatR32I    = PixelFormat{Channels: 1, Type: ComponentInt32}
+)
+
+var componentInfo = map[ComponentType]struct {
+	size     int
+	dataType uint32
+	kind     reflect.Kind
+	integer  bool
+}{
+	ComponentUint8:   {1, gl.UNSIGNED_BYTE, reflect.Uint8, true},
+	ComponentInt8:    {1, gl.BYTE, reflect.Int8, true},
+	ComponentUint16:  {2, gl.UNSIGNED_SHORT, reflect.Uint16, true},
+	ComponentInt16:   {2, gl.SHORT, reflect.Int16, true},
+	ComponentUint32:  {4, gl.UNSIGNED_INT, reflect.Uint32, true},
+	ComponentInt32:   {4, gl.INT, reflect.Int32, true},
+	ComponentFloat16: {2, gl.HALF_FLOAT, reflect.Uint16, false},
+	ComponentFloat32: {4, gl.FLOAT, reflect.Float32, false},
+}
+
+//Internal formats indexed by channel count - 1
+var (
+	internalUnorm8  = [4]int32{gl.R8, gl.RG8, gl.RGB8, gl.RGBA8}
+	internalSnorm8  = [4]int32{gl.R8_SNORM, gl.RG8_SNORM, gl.RGB8_SNORM, gl.RGBA8_SNORM}
+	internalUnorm16 = [4]int32{gl.R16, gl.RG16, gl.RGB16, gl.RGBA16}
+	internalSnorm16 = [4]int32{gl.R16_SNORM, gl.RG16_SNORM, gl.RGB16_SNORM, gl.RGBA16_SNORM}
+	internalUint8   = [4]int32{gl.R8UI, gl.RG8UI, gl.RGB8UI, gl.RGBA8UI}
+	internalInt8    = [4]int32{gl.R8I, gl.RG8I, gl.RGB8I, gl.RGBA8I}
+	internalUint16  = [4]int32{gl.R16UI, gl.RG16UI, gl.RGB16UI, gl.RGBA16UI}
+	internalInt16   = [4]int32{gl.R16I, gl.RG16I, gl.RGB16I, gl.RGBA16I}
+	internalUint32  = [4]int32{gl.R32UI, gl.RG32UI, gl.RGB32UI, gl.RGBA32UI}
+	internalInt32   = [4]int32{gl.R32I, gl.RG32I, gl.RGB32I, gl.RGBA32I}
+	internalFloat16 = [4]int32{gl.R16F, gl.RG16F, gl.RGB16F, gl.RGBA16F}
+	internalFloat32 = [4]int32{gl.R32F, gl.RG32F, gl.RGB32F, gl.RGBA32F}
+)
+
+type FormatErr struct {
+	Format PixelFormat
+	Reason string
+}
+
+func (ferr FormatErr) Error() string {
+	return fmt.Sprintf("Invalid pixel format %+v: %v", ferr.Format, ferr.Reason)
+}
+
+type SizeErr struct {
+	Expected int
+	Actual   int
+}
+
+func (serr SizeErr) Error() string {
+	return fmt.Sprintf("Invalid pixel data size %v bytes, expected %v bytes", serr.Actual, serr.Expected)
+}
+
+func (pf PixelFormat) Validate() error {
+	if pf.Channels < 1 || pf.Channels > 4 {
+		return FormatErr{Format: pf, Reason: "the channel count has to be between 1 and 4"}
+	}
+	if _, ok := componentInfo[pf.Type]; !ok {
+		return FormatErr{Format: pf, Reason: "unknown component type"}
+	}
+	if pf.SRGB && (pf.Type != ComponentUint8 || !pf.Normalized || pf.Channels < 3) {
+		return FormatErr{Format: pf, Reason: "sRGB requires 3 or 4 normalized uint8 components"}
+	}
+	if pf.Normalized && (pf.Type == ComponentUint32 || pf.Type == ComponentInt32) {
+		return FormatErr{Format: pf, Reason: "32 bit integers can't be normalized"}
+	}
+	return nil
+}
+
+//The size of one component in bytes
+func (pf PixelFormat) ComponentSize() int {
+	return componentInfo[pf.Type].size
+}
+
+//The size of one pixel in bytes
+func (pf PixelFormat) PixelSize() int {
+	return pf.Channels * pf.ComponentSize()
+}
+
+func (pf PixelFormat) isInteger() bool {
+	return componentInfo[pf.Type].integer && !pf.Normalized
+}
+
+//The sized internal format of the texture
+func (pf PixelFormat) InternalFormat() (int32, error) {
+	if err := pf.Validate(); err != nil {
+		return 0, err
+	}
+	i := pf.Channels - 1
+	if pf.SRGB {
+		if pf.Channels == 3 {
+			return gl.SRGB8, nil
+		}
+		return gl.SRGB8_ALPHA8, nil
+	}
+
+	var formats [4]int32
+	switch pf.Type {
+	case ComponentUint8:
+		formats = internalUint8
+		if pf.Normalized {
+			formats = internalUnorm8
+		}
+	case ComponentInt8:
+		formats = internalInt8
+		if pf.Normalized {
+			formats = internalSnorm8
+		}
+	case ComponentUint16:
+		formats = internalUint16
+		if pf.Normalized {
+			formats = internalUnorm16
+		}
+	case ComponentInt16:
+		formats = internalInt16
+		if pf.Normalized {
+			formats = internalSnorm16
+		}
+	case ComponentUint32:
+		formats = internalUint32
+	case ComponentInt32:
+		formats = internalInt32
+	case ComponentFloat16:
+		formats = internalFloat16
+	case ComponentFloat32:
+		formats = internalFloat32
+	}
+	return formats[i], nil
+}
+
+//The client pixel format, e.g. gl.RGBA or gl.RED_INTEGER
+func (pf PixelFormat) Format() uint32 {
+	if pf.isInteger() {
+		return [...]uint32{gl.RED_INTEGER, gl.RG_INTEGER, gl.RGB_INTEGER, gl.RGBA_INTEGER}[pf.Channels-1]
+	}
+	return [...]uint32{gl.RED, gl.RG, gl.RGB, gl.RGBA}[pf.Channels-1]
+}
+
+//The client data type, e.g. gl.UNSIGNED_BYTE
+func (pf PixelFormat) DataType() uint32 {
+	return componentInfo[pf.Type].dataType
+}
+
+/*
+	Returns the size of pixels in bytes.
+	Slices of the component type and byte slices are allowed, e.g. []float32 or []byte for ComponentFloat32.
+*/
+func (pf PixelFormat) dataSize(pixels interface{}) (int, error) {
+	value := reflect.ValueOf(pixels)
+	if value.Kind() != reflect.Slice {
+		return 0, FormatErr{Format: pf, Reason: fmt.Sprintf("pixel data has to be a slice, got %T", pixels)}
+	}
+	kind := value.Type().Elem().Kind()
+	if kind != reflect.Uint8 && kind != componentInfo[pf.Type].kind {
+		return 0, FormatErr{Format: pf, Reason: fmt.Sprintf("pixel data of type %T doesn't match the component type", pixels)}
+	}
+	return value.Len() * int(value.Type().Elem().Size()), nil
+}
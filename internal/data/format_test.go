@@ -0,0 +1,83 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternalFormat(t *testing.T) {
+	tests := []struct {
+		format   data.PixelFormat
+		internal int32
+		client   uint32
+		dataType uint32
+	}{
+		{data.FormatR8, gl.R8, gl.RED, gl.UNSIGNED_BYTE},
+		{data.FormatRGBA8, gl.RGBA8, gl.RGBA, gl.UNSIGNED_BYTE},
+		{data.FormatSRGB8, gl.SRGB8, gl.RGB, gl.UNSIGNED_BYTE},
+		{data.FormatSRGBA8, gl.SRGB8_ALPHA8, gl.RGBA, gl.UNSIGNED_BYTE},
+		{data.FormatRGBA16, gl.RGBA16, gl.RGBA, gl.UNSIGNED_SHORT},
+		{data.FormatRGBA16F, gl.RGBA16F, gl.RGBA, gl.HALF_FLOAT},
+		{data.FormatRGB32F, gl.RGB32F, gl.RGB, gl.FLOAT},
+		{data.FormatR8UI, gl.R8UI, gl.RED_INTEGER, gl.UNSIGNED_BYTE},
+		{data.FormatR32I, gl.R32I, gl.RED_INTEGER, gl.INT},
+		{data.PixelFormat{Channels: 2, Type: data.ComponentInt8, Normalized: true}, gl.RG8_SNORM, gl.RG, gl.BYTE},
+	}
+
+	for _, test := range tests {
+		internal, err := test.format.InternalFormat()
+		assert.NoError(t, err, "%+v", test.format)
+		assert.Equal(t, test.internal, internal, "%+v", test.format)
+		assert.Equal(t, test.client, test.format.Format(), "%+v", test.format)
+		assert.Equal(t, test.dataType, test.format.DataType(), "%+v", test.format)
+	}
+
+	assert.Equal(t, 16, data.FormatRGBA32F.PixelSize())
+	assert.Equal(t, 2, data.FormatR16F.PixelSize())
+}
+
+func TestInvalidFormat(t *testing.T) {
+	invalid := []data.PixelFormat{
+		{Channels: 0, Type: data.ComponentUint8},
+		{Channels: 5, Type: data.ComponentUint8},
+		{Channels: 1, Type: data.ComponentType(100)},
+		{Channels: 1, Type: data.ComponentUint8, Normalized: true, SRGB: true},
+		{Channels: 4, Type: data.ComponentFloat32, SRGB: true},
+		{Channels: 1, Type: data.ComponentUint32, Normalized: true},
+	}
+
+	for _, format := range invalid {
+		_, err := format.InternalFormat()
+		assert.IsType(t, data.FormatErr{}, err, "%+v", format)
+	}
+}
+
+//Validation happens before any gl call, so no context is needed
+func TestPixelsValidation(t *testing.T) {
+	tex := &data.Texture{Target: data.Texture2D}
+
+	err := tex.AllocPixels(0, data.FormatRGBA8, 4, 4, 0, make([]byte, 10))
+	assert.Equal(t, data.SizeErr{Expected: 64, Actual: 10}, err)
+
+	err = tex.AllocPixels(0, data.FormatRGBA32F, 2, 2, 0, make([]float32, 8))
+	assert.Equal(t, data.SizeErr{Expected: 64, Actual: 32}, err)
+
+	err = tex.AllocPixels(0, data.FormatRGBA32F, 2, 2, 0, make([]uint16, 16))
+	assert.IsType(t, data.FormatErr{}, err)
+
+	err = tex.AllocPixels(0, data.FormatR8, 2, 2, 0, "pixels")
+	assert.IsType(t, data.FormatErr{}, err)
+
+	//The last row doesn't have to be padded to the row length
+	err = tex.SubPixels(0, data.FormatR8, 0, 0, 0, 2, 2, 0, 4, make([]byte, 5))
+	assert.Equal(t, data.SizeErr{Expected: 6, Actual: 5}, err)
+
+	err = tex.SubPixels(0, data.FormatR8, 0, 0, 0, 4, 1, 0, 2, make([]byte, 4))
+	assert.IsType(t, data.FormatErr{}, err)
+
+	err = tex.AllocWithBytes(make([]byte, 3), 2, 2, 0, gl.RGB8, gl.RGB)
+	assert.Equal(t, data.SizeErr{Expected: 12, Actual: 3}, err)
+}
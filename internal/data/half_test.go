@@ -0,0 +1,43 @@
+package data_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHalfFloat(t *testing.T) {
+	tests := []struct {
+		value float32
+		half  uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},
+		{1.0 / 3, 0x3555},
+		{float32(math.Pow(2, -14)), 0x0400},
+		{float32(math.Pow(2, -24)), 0x0001},
+		{float32(math.Inf(1)), 0x7c00},
+		{float32(math.Inf(-1)), 0xfc00},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.half, data.Float32ToHalf(test.value), "%v", test.value)
+		if test.value != 1.0/3 {
+			assert.Equal(t, test.value, data.HalfToFloat32(test.half), "%#04x", test.half)
+		}
+	}
+
+	//Rounding
+	assert.Equal(t, uint16(0x7c00), data.Float32ToHalf(70000))
+	assert.Equal(t, uint16(0x3c00), data.Float32ToHalf(1+1.0/4096))
+	assert.Equal(t, uint16(0x3c02), data.Float32ToHalf(1+3.0/2048))
+	assert.Equal(t, uint16(0), data.Float32ToHalf(float32(math.Pow(2, -26))))
+	assert.True(t, math.IsNaN(float64(data.HalfToFloat32(data.Float32ToHalf(float32(math.NaN()))))))
+
+	assert.Equal(t, []uint16{0x3c00, 0x4000}, data.HalfFloats([]float32{1, 2}))
+}
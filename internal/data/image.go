@@ -0,0 +1,115 @@
+package data
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"unsafe"
+
+	"github.com/Qendolin/go-printpixel/internal/hdr"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+//Go stores 16 bit images big endian, OpenGL expects the native byte order
+var littleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+//The parameters to upload an image without converting it
+type imageUpload struct {
+	format PixelFormat
+	//The pixels of the first row starting at the image origin, a []uint8 or []float32
+	pix interface{}
+	//The distance between rows in bytes
+	stride int
+	width  int
+	height int
+	//16 bit data is big endian
+	bigEndian bool
+	//Single channel images are displayed as gray
+	gray bool
+}
+
+func newImageUpload(img image.Image) (upload imageUpload) {
+	size := img.Bounds().Size()
+	upload.width, upload.height = size.X, size.Y
+
+	switch img := img.(type) {
+	case *image.RGBA:
+		upload.format = FormatRGBA8
+		upload.setPix(img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y):], img.Stride)
+	case *image.NRGBA:
+		upload.format = FormatRGBA8
+		upload.setPix(img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y):], img.Stride)
+	case *image.RGBA64:
+		upload.format = FormatRGBA16
+		upload.setPix(img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y):], img.Stride)
+		upload.bigEndian = true
+	case *image.NRGBA64:
+		upload.format = FormatRGBA16
+		upload.setPix(img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y):], img.Stride)
+		upload.bigEndian = true
+	case *image.Gray:
+		upload.format = FormatR8
+		upload.setPix(img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y):], img.Stride)
+		upload.gray = true
+	case *image.Gray16:
+		upload.format = FormatR16
+		upload.setPix(img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y):], img.Stride)
+		upload.bigEndian = true
+		upload.gray = true
+	case *hdr.Image:
+		upload.format = FormatRGB32F
+		upload.setPix(img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y):], img.Stride*4)
+	case *image.Paletted:
+		//Expanding the palette is much faster than draw.Draw
+		palette := make([]color.NRGBA, len(img.Palette))
+		for i, c := range img.Palette {
+			palette[i] = color.NRGBAModel.Convert(c).(color.NRGBA)
+		}
+		nrgba := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+		for y := 0; y < size.Y; y++ {
+			row := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+			for x := 0; x < size.X; x++ {
+				var c color.NRGBA
+				if int(row[x]) < len(palette) {
+					c = palette[row[x]]
+				}
+				i := y*nrgba.Stride + x*4
+				nrgba.Pix[i+0] = c.R
+				nrgba.Pix[i+1] = c.G
+				nrgba.Pix[i+2] = c.B
+				nrgba.Pix[i+3] = c.A
+			}
+		}
+		return newImageUpload(nrgba)
+	default:
+		//Includes *image.YCbCr, which draw.Draw converts efficiently
+		rgba := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+		draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+		return newImageUpload(rgba)
+	}
+	return
+}
+
+func (upload *imageUpload) setPix(pix interface{}, stride int) {
+	upload.pix = pix
+	upload.stride = stride
+}
+
+//Sets the pixel store parameters for the upload, calls upload and resets them
+func (upload *imageUpload) do(target TexTarget, f func()) {
+	withUnpack(upload.stride/upload.format.PixelSize(), 0, upload.bigEndian && littleEndian, f)
+
+	//Parameters of cube map faces are set on the cube map
+	if target >= TextureCubeMapPositiveX && target <= TextureCubeMapNegativeZ {
+		target = TextureCubeMap
+	}
+	//Always set, the texture may have held a gray image before
+	swizzle := []int32{gl.RED, gl.GREEN, gl.BLUE, gl.ALPHA}
+	if upload.gray {
+		swizzle = []int32{gl.RED, gl.RED, gl.RED, gl.ONE}
+	}
+	gl.TexParameteriv(uint32(target), gl.TEXTURE_SWIZZLE_RGBA, &swizzle[0])
+}
@@ -0,0 +1,218 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNoLayers   = errors.New("At least one layer is required")
+	ErrLayerSize  = errors.New("All layers have to be of the same size")
+	ErrSpriteGrid = errors.New("The sprite grid doesn't fit into the image")
+)
+
+type FrameErr struct {
+	Path string
+	Err  error
+}
+
+func (ferr FrameErr) Error() string {
+	return fmt.Sprintf("Could not load frame %v: %v", ferr.Path, ferr.Err)
+}
+
+/*
+	Allocates a 2D array or 3D texture with one layer per image and uploads them. The texture has to be bound.
+	The images are uploaded in their native format if they all share it, otherwise they are converted to RGBA.
+*/
+func (tex *Texture) AllocLayers(images []image.Image, level int32) error {
+	switch tex.Target {
+	case Texture2DArray, Texture3D:
+	default:
+		return TargetErr{Target: tex.Target}
+	}
+	if len(images) == 0 {
+		return ErrNoLayers
+	}
+
+	size := images[0].Bounds().Size()
+	uploads := make([]imageUpload, len(images))
+	mixed := false
+	for i, img := range images {
+		if img.Bounds().Size() != size {
+			return ErrLayerSize
+		}
+		uploads[i] = newImageUpload(img)
+		mixed = mixed || uploads[i].format != uploads[0].format || uploads[i].gray != uploads[0].gray
+	}
+	if mixed {
+		for i, img := range images {
+			rgba := image.NewRGBA(image.Rectangle{Max: size})
+			draw.Draw(rgba, rgba.Rect, img, img.Bounds().Min, draw.Src)
+			uploads[i] = newImageUpload(rgba)
+		}
+	}
+
+	format := uploads[0].format
+	internalFormat, _ := format.InternalFormat()
+	tex.Alloc(level, internalFormat, int32(size.X), int32(size.Y), int32(len(images)), format.Format(), format.DataType(), nil)
+	for i, upload := range uploads {
+		upload.do(tex.Target, func() {
+			tex.SubImage(level, 0, 0, int32(i), int32(size.X), int32(size.Y), 1, format.Format(), format.DataType(), upload.pix)
+		})
+	}
+	return nil
+}
+
+//Describes the layout of the frames in a sprite sheet
+type SpriteGrid struct {
+	FrameWidth  int
+	FrameHeight int
+	//The space between adjacent frames in pixels
+	Spacing int
+	//The space around all frames in pixels
+	Margin int
+	//The number of frames, every full cell is a frame if 0
+	Count int
+}
+
+//Returns the frames of a sprite sheet row by row
+func (grid SpriteGrid) Slice(img image.Image) ([]image.Image, error) {
+	if grid.FrameWidth < 1 || grid.FrameHeight < 1 {
+		return nil, ErrSpriteGrid
+	}
+	bounds := img.Bounds()
+	cols := (bounds.Dx() - 2*grid.Margin + grid.Spacing) / (grid.FrameWidth + grid.Spacing)
+	rows := (bounds.Dy() - 2*grid.Margin + grid.Spacing) / (grid.FrameHeight + grid.Spacing)
+	count := grid.Count
+	if count == 0 {
+		count = cols * rows
+	}
+	if cols < 1 || rows < 1 || count > cols*rows {
+		return nil, ErrSpriteGrid
+	}
+
+	frames := make([]image.Image, count)
+	for i := range frames {
+		min := bounds.Min.Add(image.Pt(
+			grid.Margin+(i%cols)*(grid.FrameWidth+grid.Spacing),
+			grid.Margin+(i/cols)*(grid.FrameHeight+grid.Spacing),
+		))
+		frames[i] = subImage(img, image.Rectangle{Min: min, Max: min.Add(image.Pt(grid.FrameWidth, grid.FrameHeight))})
+	}
+	return frames, nil
+}
+
+//Slices a sprite sheet and uploads one frame per layer, see AllocLayers
+func (tex *Texture) AllocWithSpriteSheet(img image.Image, grid SpriteGrid, level int32) error {
+	frames, err := grid.Slice(img)
+	if err != nil {
+		return err
+	}
+	return tex.AllocLayers(frames, level)
+}
+
+/*
+	Loads the numbered frames in dir and uploads one frame per layer, see AllocLayers and LoadFrames.
+	The decoders of the image formats have to be registered, e.g. by importing image/png.
+*/
+func (tex *Texture) AllocWithDir(dir string, level int32) error {
+	frames, err := LoadFrames(dir)
+	if err != nil {
+		return err
+	}
+	return tex.AllocLayers(frames, level)
+}
+
+/*
+	Decodes the numbered images in dir in the order of SortFrames.
+	Files in a format without a registered decoder, e.g. notes1.txt, are skipped.
+*/
+func LoadFrames(dir string) ([]image.Image, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() {
+			names = append(names, info.Name())
+		}
+	}
+
+	var frames []image.Image
+	for _, name := range SortFrames(names) {
+		path := filepath.Join(dir, name)
+		frame, err := decodeFrame(path)
+		if err == image.ErrFormat {
+			continue
+		}
+		if err != nil {
+			return nil, FrameErr{Path: path, Err: err}
+		}
+		frames = append(frames, frame)
+	}
+	if len(frames) == 0 {
+		return nil, ErrNoLayers
+	}
+	return frames, nil
+}
+
+func decodeFrame(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	return img, err
+}
+
+var frameNumber = regexp.MustCompile(`\d+`)
+
+/*
+	Returns the names that contain a number, sorted by their last number so frame_2.png comes before frame_10.png.
+	Hidden files are skipped and names with the same number are sorted alphabetically.
+*/
+func SortFrames(names []string) []string {
+	type frame struct {
+		name   string
+		number int
+	}
+	var frames []frame
+	for _, name := range names {
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		numbers := frameNumber.FindAllString(base, -1)
+		if len(numbers) == 0 {
+			continue
+		}
+		number, err := strconv.Atoi(numbers[len(numbers)-1])
+		if err != nil {
+			continue
+		}
+		frames = append(frames, frame{name: name, number: number})
+	}
+
+	sort.Slice(frames, func(i, j int) bool {
+		if frames[i].number != frames[j].number {
+			return frames[i].number < frames[j].number
+		}
+		return frames[i].name < frames[j].name
+	})
+	sorted := make([]string, len(frames))
+	for i, f := range frames {
+		sorted[i] = f.name
+	}
+	return sorted
+}
@@ -0,0 +1,133 @@
+package data_test
+
+import (
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/Qendolin/go-printpixel/internal/utils"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortFrames(t *testing.T) {
+	names := []string{"frame_10.png", "frame_2.png", "readme.txt", ".frame_0.png", "frame_1.png", "walk_v2_3.png"}
+	assert.Equal(t, []string{"frame_1.png", "frame_2.png", "walk_v2_3.png", "frame_10.png"}, data.SortFrames(names))
+}
+
+func TestSpriteGrid(t *testing.T) {
+	sheet := image.NewRGBA(image.Rect(0, 0, 11, 7))
+	sheet.SetRGBA(7, 4, color.RGBA{255, 0, 0, 255})
+
+	grid := data.SpriteGrid{FrameWidth: 4, FrameHeight: 4, Spacing: 1, Margin: 1}
+	frames, err := grid.Slice(sheet)
+	assert.NoError(t, err)
+	assert.Len(t, frames, 2)
+	assert.Equal(t, image.Rect(6, 1, 10, 5), frames[1].Bounds())
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, frames[1].At(7, 4))
+
+	grid.Count = 1
+	frames, err = grid.Slice(sheet)
+	assert.NoError(t, err)
+	assert.Len(t, frames, 1)
+
+	grid.Count = 3
+	_, err = grid.Slice(sheet)
+	assert.Equal(t, data.ErrSpriteGrid, err)
+}
+
+func layerCount(tex *data.Texture) int32 {
+	var depth int32
+	gl.GetTexLevelParameteriv(uint32(tex.Target), 0, gl.TEXTURE_DEPTH, &depth)
+	return depth
+}
+
+func TestAllocLayers(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	tex := data.NewTexture(data.Texture2DArray)
+	defer tex.Destroy()
+	tex.Bind(0)
+
+	layers := []image.Image{
+		image.NewRGBA(image.Rect(0, 0, 4, 4)),
+		image.NewGray(image.Rect(0, 0, 4, 4)),
+		image.NewNRGBA64(image.Rect(0, 0, 4, 4)),
+	}
+	assert.NoError(t, tex.AllocLayers(layers, 0))
+	assert.NoError(t, data.CheckGlError("layer upload"))
+	assert.Equal(t, int32(3), layerCount(tex))
+
+	layers = append(layers, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	assert.Equal(t, data.ErrLayerSize, tex.AllocLayers(layers, 0))
+	assert.Equal(t, data.TargetErr{Target: data.Texture2D}, data.NewTexture(data.Texture2D).AllocLayers(layers, 0))
+}
+
+func TestAllocWithDir(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	dir, err := ioutil.TempDir("", "frames")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"frame_1.png", "frame_2.png", "frame_10.png"} {
+		if err := utils.SavePng(filepath.Join(dir, name), image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "notes1.txt"), []byte("not a frame"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tex := data.NewTexture(data.Texture3D)
+	defer tex.Destroy()
+	tex.Bind(0)
+	assert.NoError(t, tex.AllocWithDir(dir, 0))
+	assert.NoError(t, data.CheckGlError("frame upload"))
+	assert.Equal(t, int32(3), layerCount(tex))
+}
+
+func TestLoadFrames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "frames")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = data.LoadFrames(dir)
+	assert.Equal(t, data.ErrNoLayers, err)
+
+	for i, name := range []string{"frame_2.png", "frame_1.png"} {
+		if err := utils.SavePng(filepath.Join(dir, name), image.NewRGBA(image.Rect(0, 0, i+1, 1))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	//Stray numbered files that are not images are skipped
+	for _, name := range []string{"notes1.txt", "thumbs2.db"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("not a frame"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	frames, err := data.LoadFrames(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, frames, 2) {
+		assert.Equal(t, image.Rect(0, 0, 2, 1), frames[0].Bounds())
+		assert.Equal(t, image.Rect(0, 0, 1, 1), frames[1].Bounds())
+	}
+
+	//A frame that is an image but can't be decoded still fails
+	if err := ioutil.WriteFile(filepath.Join(dir, "frame_3.png"), []byte("\x89PNG\r\n\x1a\ntruncated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = data.LoadFrames(dir)
+	assert.IsType(t, data.FrameErr{}, err)
+}
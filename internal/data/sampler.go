@@ -0,0 +1,145 @@
+package data
+
+import (
+	"image/color"
+
+	"github.com/Qendolin/go-printpixel/internal/context"
+	"github.com/Qendolin/go-printpixel/internal/tracker"
+	"github.com/Qendolin/go-printpixel/internal/utils"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+type TexCompareFunc int
+
+//Depth texture compare functions
+const (
+	CompareLessEqual    = TexCompareFunc(gl.LEQUAL)
+	CompareGreaterEqual = TexCompareFunc(gl.GEQUAL)
+	CompareLess         = TexCompareFunc(gl.LESS)
+	CompareGreater      = TexCompareFunc(gl.GREATER)
+	CompareEqual        = TexCompareFunc(gl.EQUAL)
+	CompareNotEqual     = TexCompareFunc(gl.NOTEQUAL)
+	CompareAlways       = TexCompareFunc(gl.ALWAYS)
+	CompareNever        = TexCompareFunc(gl.NEVER)
+)
+
+const extAnisotropic = "GL_EXT_texture_filter_anisotropic"
+
+//Sampling parameters that override those of the texture bound to the same unit
+type Sampler struct {
+	*uint32
+}
+
+func NewSampler() *Sampler {
+	id := new(uint32)
+	gl.GenSamplers(1, id)
+	tracker.Track(tracker.Sampler, *id)
+	return &Sampler{id}
+}
+
+func (smp *Sampler) Id() uint32 {
+	return *smp.uint32
+}
+
+func (smp *Sampler) Bind(unit int) {
+	gl.BindSampler(uint32(unit), smp.Id())
+}
+
+func (smp *Sampler) Unbind(unit int) {
+	gl.BindSampler(uint32(unit), 0)
+}
+
+func (smp *Sampler) BindFor(unit int, context utils.BindingClosure) {
+	smp.Bind(unit)
+	defered := context()
+	smp.Unbind(unit)
+	for _, deferedFunc := range defered {
+		deferedFunc()
+	}
+}
+
+//Modes that are 0 are left unchanged
+func (smp *Sampler) WrapMode(sMode, tMode, rMode TexWrapMode) {
+	if sMode != 0 {
+		gl.SamplerParameteri(smp.Id(), gl.TEXTURE_WRAP_S, int32(sMode))
+	}
+	if tMode != 0 {
+		gl.SamplerParameteri(smp.Id(), gl.TEXTURE_WRAP_T, int32(tMode))
+	}
+	if rMode != 0 {
+		gl.SamplerParameteri(smp.Id(), gl.TEXTURE_WRAP_R, int32(rMode))
+	}
+}
+
+//Modes that are 0 are left unchanged
+func (smp *Sampler) FilterMode(minMode, magMode TexFilterMode) {
+	if minMode != 0 {
+		gl.SamplerParameteri(smp.Id(), gl.TEXTURE_MIN_FILTER, int32(minMode))
+	}
+	if magMode != 0 {
+		gl.SamplerParameteri(smp.Id(), gl.TEXTURE_MAG_FILTER, int32(magMode))
+	}
+}
+
+//The color used with WrapClampToBorder
+func (smp *Sampler) BorderColor(c color.Color) {
+	nc := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	border := []float32{
+		float32(nc.R) / 0xffff,
+		float32(nc.G) / 0xffff,
+		float32(nc.B) / 0xffff,
+		float32(nc.A) / 0xffff,
+	}
+	gl.SamplerParameterfv(smp.Id(), gl.TEXTURE_BORDER_COLOR, &border[0])
+}
+
+//Limits the mipmap levels that are sampled, the defaults are -1000 and 1000
+func (smp *Sampler) LodRange(min, max float32) {
+	gl.SamplerParameterf(smp.Id(), gl.TEXTURE_MIN_LOD, min)
+	gl.SamplerParameterf(smp.Id(), gl.TEXTURE_MAX_LOD, max)
+}
+
+//Is added to the computed mipmap level
+func (smp *Sampler) LodBias(bias float32) {
+	gl.SamplerParameterf(smp.Id(), gl.TEXTURE_LOD_BIAS, bias)
+}
+
+/*
+	Enables comparing depth textures with the reference value, used by shadow samplers.
+
+	fn - the result is 1 if it is true for the reference and the texture value, ignored if disabled
+*/
+func (smp *Sampler) CompareMode(enabled bool, fn TexCompareFunc) {
+	if !enabled {
+		gl.SamplerParameteri(smp.Id(), gl.TEXTURE_COMPARE_MODE, gl.NONE)
+		return
+	}
+	gl.SamplerParameteri(smp.Id(), gl.TEXTURE_COMPARE_MODE, gl.COMPARE_REF_TO_TEXTURE)
+	gl.SamplerParameteri(smp.Id(), gl.TEXTURE_COMPARE_FUNC, int32(fn))
+}
+
+/*
+	Sets the maximum degree of anisotropic filtering, 1 disables it.
+	The level is clamped to the supported maximum. Returns false if the extension is not supported.
+*/
+func (smp *Sampler) Anisotropy(level float32) bool {
+	if !context.ExtensionSupported(extAnisotropic) {
+		return false
+	}
+	var max float32
+	gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &max)
+	if level > max {
+		level = max
+	}
+	if level < 1 {
+		level = 1
+	}
+	gl.SamplerParameterf(smp.Id(), gl.TEXTURE_MAX_ANISOTROPY, level)
+	return true
+}
+
+func (smp *Sampler) Destroy() {
+	tracker.Untrack(tracker.Sampler, smp.Id())
+	gl.DeleteSamplers(1, smp.uint32)
+	smp.uint32 = nil
+}
@@ -0,0 +1,124 @@
+package data
+
+import (
+	"errors"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+var (
+	ErrMapFailed     = errors.New("Failed to map the pixel unpack buffer")
+	ErrStreamCorrupt = errors.New("The pixel unpack buffer was corrupted while it was mapped")
+)
+
+//A Stream uploads whole texture images through a ring of pixel unpack buffers,
+//so the cpu can write the next frame while the gpu is still reading the previous ones.
+type Stream struct {
+	Texture       *Texture
+	buffers       []*Vbo
+	fences        []uintptr
+	next          int
+	width, height int32
+	format        PixelFormat
+	size          int
+}
+
+/*
+	Allocates the storage of tex. The texture is left bound to unit 0.
+
+	bufferCount - the number of buffers in the ring, usually 2 or 3
+*/
+func NewStream(tex *Texture, width, height int32, format PixelFormat, bufferCount int) (*Stream, error) {
+	if bufferCount < 1 {
+		bufferCount = 1
+	}
+	size := int(width) * int(height) * format.PixelSize()
+
+	tex.Bind(0)
+	if err := tex.AllocPixels(0, format, int(width), int(height), 1, nil); err != nil {
+		return nil, err
+	}
+
+	buffers := make([]*Vbo, bufferCount)
+	for i := range buffers {
+		buffers[i] = NewVbo()
+		buffers[i].BindFor(gl.PIXEL_UNPACK_BUFFER, func() []func() {
+			buffers[i].Reserve(gl.PIXEL_UNPACK_BUFFER, size, gl.STREAM_DRAW)
+			return nil
+		})
+	}
+
+	return &Stream{
+		Texture: tex,
+		buffers: buffers,
+		fences:  make([]uintptr, bufferCount),
+		width:   width,
+		height:  height,
+		format:  format,
+		size:    size,
+	}, nil
+}
+
+//The size of one frame in bytes
+func (s *Stream) Size() int {
+	return s.size
+}
+
+//Uploads a tightly packed frame. The texture is left bound to unit 0.
+func (s *Stream) Write(pixels []byte) error {
+	if len(pixels) != s.size {
+		return SizeErr{Expected: s.size, Actual: len(pixels)}
+	}
+	return s.WriteFunc(func(buf []byte) {
+		copy(buf, pixels)
+	})
+}
+
+/*
+	Like Write but lets fill write the frame directly into the mapped buffer.
+
+	fill - must write the whole frame and must not keep a reference to buf
+*/
+func (s *Stream) WriteFunc(fill func(buf []byte)) (err error) {
+	buf := s.buffers[s.next]
+	buf.Bind(gl.PIXEL_UNPACK_BUFFER)
+	defer buf.Unbind(gl.PIXEL_UNPACK_BUFFER)
+
+	//Wait until the gpu has finished reading the buffer from its last use
+	if fence := s.fences[s.next]; fence != 0 {
+		gl.ClientWaitSync(fence, gl.SYNC_FLUSH_COMMANDS_BIT, gl.TIMEOUT_IGNORED)
+		gl.DeleteSync(fence)
+		s.fences[s.next] = 0
+	}
+
+	mapped := buf.MapRange(gl.PIXEL_UNPACK_BUFFER, 0, s.size, gl.MAP_WRITE_BIT|gl.MAP_INVALIDATE_BUFFER_BIT|gl.MAP_UNSYNCHRONIZED_BIT)
+	if mapped == nil {
+		return ErrMapFailed
+	}
+	fill(mapped)
+	if !buf.Unmap(gl.PIXEL_UNPACK_BUFFER) {
+		return ErrStreamCorrupt
+	}
+
+	s.Texture.Bind(0)
+	//The data is read from offset 0 of the bound unpack buffer
+	withUnpack(0, 0, false, func() {
+		s.Texture.SubImage(0, 0, 0, 0, s.width, s.height, 0, s.format.Format(), s.format.DataType(), nil)
+	})
+	s.fences[s.next] = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+
+	s.next = (s.next + 1) % len(s.buffers)
+	return
+}
+
+//Destroys the buffers, but not the texture
+func (s *Stream) Destroy() {
+	for i, buf := range s.buffers {
+		if s.fences[i] != 0 {
+			gl.DeleteSync(s.fences[i])
+		}
+		buf.Destroy()
+	}
+	s.buffers = nil
+	s.fences = nil
+}
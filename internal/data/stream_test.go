@@ -0,0 +1,98 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/canvas"
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	streamWidth  = 1920
+	streamHeight = 1080
+)
+
+func TestStream(t *testing.T) {
+	win, close := test.NewWindow(t)
+	defer close()
+
+	tex := data.NewTexture(data.Texture2D)
+	stream, err := data.NewStream(tex, 100, 100, data.FormatRGBA8, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Destroy()
+	tex.FilterMode(data.FilterNearest, data.FilterNearest)
+
+	err = stream.Write(make([]byte, 10))
+	assert.Equal(t, data.SizeErr{Expected: 100 * 100 * 4, Actual: 10}, err)
+
+	prog := test.NewProgram(t, "assets/shaders/quad_tex.vert", "assets/shaders/quad_tex.frag")
+	cnv := canvas.NewCanvasWithProgram(prog)
+
+	frame := 0
+	for !win.ShouldClose() {
+		err := stream.WriteFunc(func(buf []byte) {
+			for i := 0; i < len(buf); i += 4 {
+				buf[i+0] = byte(i / 4 % 100 * 2)
+				buf[i+1] = byte(frame * 20)
+				buf[i+2] = 0
+				buf[i+3] = 255
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cnv.BindFor(func() []func() {
+			cnv.Draw()
+			return nil
+		})
+		win.SwapBuffers()
+		glfw.PollEvents()
+		frame++
+	}
+}
+
+func BenchmarkStreamWrite(b *testing.B) {
+	_, close := test.NewWindow(b)
+	defer close()
+
+	tex := data.NewTexture(data.Texture2D)
+	stream, err := data.NewStream(tex, streamWidth, streamHeight, data.FormatRGBA8, 3)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer stream.Destroy()
+	pixels := make([]byte, stream.Size())
+
+	b.SetBytes(int64(len(pixels)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := stream.Write(pixels); err != nil {
+			b.Fatal(err)
+		}
+		gl.Flush()
+	}
+	gl.Finish()
+}
+
+func BenchmarkAllocWrite(b *testing.B) {
+	_, close := test.NewWindow(b)
+	defer close()
+
+	tex := data.NewTexture(data.Texture2D)
+	tex.Bind(0)
+	pixels := make([]byte, streamWidth*streamHeight*4)
+
+	b.SetBytes(int64(len(pixels)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tex.Alloc(0, gl.RGBA8, streamWidth, streamHeight, 0, gl.RGBA, gl.UNSIGNED_BYTE, pixels)
+		gl.Flush()
+	}
+	gl.Finish()
+}
@@ -1,10 +1,13 @@
 package data
 
 import (
+	"errors"
+	"fmt"
 	"image"
-	"image/draw"
 	"io"
+	"unsafe"
 
+	"github.com/Qendolin/go-printpixel/internal/tracker"
 	"github.com/Qendolin/go-printpixel/internal/utils"
 	"github.com/go-gl/gl/v3.3-core/gl"
 )
@@ -52,6 +55,7 @@ const (
 	TextureProxy1DArray     = TexTarget(gl.PROXY_TEXTURE_1D_ARRAY)
 	TextureRectangle        = TexTarget(gl.TEXTURE_RECTANGLE)
 	TextureProxyRectangle   = TexTarget(gl.PROXY_TEXTURE_RECTANGLE)
+	TextureCubeMap          = TexTarget(gl.TEXTURE_CUBE_MAP)
 	TextureCubeMapPositiveX = TexTarget(gl.TEXTURE_CUBE_MAP_POSITIVE_X)
 	TextureCubeMapPositiveY = TexTarget(gl.TEXTURE_CUBE_MAP_POSITIVE_Y)
 	TextureCubeMapPositiveZ = TexTarget(gl.TEXTURE_CUBE_MAP_POSITIVE_Z)
@@ -69,6 +73,16 @@ const (
 	TextureProxy2DArray = TexTarget(gl.PROXY_TEXTURE_2D_ARRAY)
 )
 
+var ErrNoImage = errors.New("The texture level has no image")
+
+type TargetErr struct {
+	Target TexTarget
+}
+
+func (terr TargetErr) Error() string {
+	return fmt.Sprintf("Unsupported texture target 0x%04X", int(terr.Target))
+}
+
 type Texture struct {
 	*uint32
 	Target TexTarget
@@ -77,6 +91,7 @@ type Texture struct {
 func NewTexture(texType TexTarget) *Texture {
 	id := new(uint32)
 	gl.GenTextures(1, id)
+	tracker.Track(tracker.Texture, *id)
 	return &Texture{uint32: id, Target: texType}
 }
 
@@ -144,54 +159,244 @@ func (tex *Texture) Alloc(level, internalFormat, width, height, depth int32, for
 	} else {
 		gl.TexImage2D(uint32(tex.Target), level, internalFormat, width, height, 0, format, dataType, dataPtr)
 	}
+
+	w, h, d := tex.extent(int(width), int(height), int(depth))
+	tex.trackImage(level, w*h*d*estimatePixelSize(internalFormat, format, dataType))
 }
 
-func (tex *Texture) AllocWithFile2D(file io.Reader, level, internalFormat int32, format, dataType uint32) error {
+/*
+	Allocates a texture image from compressed data, e.g. BCn or ETC2 blocks.
+
+	internalFormat - a specific compressed format like gl.COMPRESSED_RGBA_S3TC_DXT5_EXT
+*/
+func (tex *Texture) AllocCompressed(level int32, internalFormat uint32, width, height, depth int32, data []byte) {
+	var dataPtr unsafe.Pointer
+	if len(data) > 0 {
+		dataPtr = gl.Ptr(data)
+	}
+	size := int32(len(data))
+	if tex.Target == Texture1D || tex.Target == TextureProxy1D {
+		gl.CompressedTexImage1D(uint32(tex.Target), level, internalFormat, width, 0, size, dataPtr)
+	} else if tex.Target == Texture3D || tex.Target == TextureProxy3D || tex.Target == Texture2DArray || tex.Target == TextureProxy2DArray {
+		gl.CompressedTexImage3D(uint32(tex.Target), level, internalFormat, width, height, depth, 0, size, dataPtr)
+	} else {
+		gl.CompressedTexImage2D(uint32(tex.Target), level, internalFormat, width, height, 0, size, dataPtr)
+	}
+	tex.trackImage(level, len(data))
+}
+
+//Replaces a region of an already allocated texture image without reallocating it
+func (tex *Texture) SubImage(level, xOffset, yOffset, zOffset, width, height, depth int32, format, dataType uint32, data interface{}) {
+	dataPtr := gl.Ptr(data)
+	if tex.Target == Texture1D {
+		gl.TexSubImage1D(uint32(tex.Target), level, xOffset, width, format, dataType, dataPtr)
+	} else if tex.Target == Texture3D || tex.Target == Texture2DArray {
+		gl.TexSubImage3D(uint32(tex.Target), level, xOffset, yOffset, zOffset, width, height, depth, format, dataType, dataPtr)
+	} else {
+		gl.TexSubImage2D(uint32(tex.Target), level, xOffset, yOffset, width, height, format, dataType, dataPtr)
+	}
+}
+
+//Decodes an image and uploads it in its native format, see AllocWithImage
+func (tex *Texture) AllocWithFile2D(file io.Reader, level int32) error {
 	img, _, err := image.Decode(file)
 	if err != nil {
 		return err
 	}
 
-	rgba := image.NewRGBA(img.Bounds())
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
-	size := img.Bounds().Size()
-	tex.Alloc(level, internalFormat, int32(size.X), int32(size.Y), 0, format, dataType, rgba.Pix)
+	tex.AllocWithImage(img, level)
 	return nil
 }
 
-func (tex *Texture) AllocWithFile1D(file io.Reader, level, internalFormat int32, format, dataType uint32) error {
+//Decodes an image and uploads its first row in its native format, see AllocWithImage
+func (tex *Texture) AllocWithFile1D(file io.Reader, level int32) error {
 	img, _, err := image.Decode(file)
 	if err != nil {
 		return err
 	}
 
-	rgba := image.NewRGBA(img.Bounds())
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
-	size := img.Bounds().Size()
-	tex.Alloc(level, internalFormat, int32(size.X), 0, 0, format, dataType, rgba.Pix)
+	tex.AllocWithImage(img, level)
 	return nil
 }
 
 /*
 	files - right (+x), left (-x), top (+y), bottom (-y), back (+z), front (-z)
+
+	Deprecated: Use CubeMap.AllocFaces, tex has to be bound as TextureCubeMap anyway.
 */
-func (tex *Texture) AllocWithFile3D(files [6]io.Reader, level, internalFormat int32, format, dataType uint32) error {
+func (tex *Texture) AllocWithFile3D(files [6]io.Reader, level int32) error {
 	for i, file := range files {
-		err := tex.As(TexTarget(int(TextureCubeMapPositiveX)+i)).AllocWithFile2D(file, level, internalFormat, format, dataType)
+		err := tex.As(TexTarget(int(TextureCubeMapPositiveX)+i)).AllocWithFile2D(file, level)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	Uploads an image in its native format, so no intermediate copy is needed.
+	*image.RGBA, *image.NRGBA, *image.RGBA64, *image.NRGBA64, *image.Gray and *image.Gray16 are uploaded directly,
+	gray images are displayed as gray instead of red. *image.Paletted is expanded to NRGBA and other images are converted to RGBA.
+	*hdr.Image is uploaded as RGB32F.
+	A 1D texture only uses the first row.
+*/
+func (tex *Texture) AllocWithImage(img image.Image, level int32) {
+	upload := newImageUpload(img)
+	internalFormat, _ := upload.format.InternalFormat()
+	upload.do(tex.Target, func() {
+		tex.Alloc(level, internalFormat, int32(upload.width), int32(upload.height), 0, upload.format.Format(), upload.format.DataType(), upload.pix)
+	})
+}
+
+var formatChannels = map[uint32]int{
+	gl.RED:  1,
+	gl.RG:   2,
+	gl.RGB:  3,
+	gl.BGR:  3,
+	gl.RGBA: 4,
+	gl.BGRA: 4,
+}
+
+//Uploads tightly packed unsigned bytes, the number of channels is determined by format
+func (tex *Texture) AllocWithBytes(bytes []byte, width, height int32, level, internalFormat int32, format uint32) error {
+	channels, ok := formatChannels[format]
+	if !ok {
+		return FormatErr{Reason: fmt.Sprintf("unsupported client format 0x%04X", format)}
+	}
+	pf := PixelFormat{Channels: channels, Type: ComponentUint8, Normalized: true}
+	w, h, d := tex.extent(int(width), int(height), 1)
+	if expected := w * h * d * pf.PixelSize(); len(bytes) != expected {
+		return SizeErr{Expected: expected, Actual: len(bytes)}
+	}
+
+	withUnpack(0, 0, false, func() {
+		tex.Alloc(level, internalFormat, width, height, 0, format, gl.UNSIGNED_BYTE, bytes)
+	})
+	return nil
+}
+
+/*
+	Allocates a texture level and uploads tightly packed pixels after validating their size.
+	The height is ignored for 1D textures and the depth for 1D and 2D textures.
+
+	pixels - a slice of the component type or a byte slice, nil leaves the level uninitialized
+*/
+func (tex *Texture) AllocPixels(level int32, pf PixelFormat, width, height, depth int, pixels interface{}) error {
+	internalFormat, err := pf.InternalFormat()
+	if err != nil {
+		return err
+	}
+	if pixels != nil {
+		size, err := pf.dataSize(pixels)
 		if err != nil {
 			return err
 		}
+		w, h, d := tex.extent(width, height, depth)
+		if expected := w * h * d * pf.PixelSize(); size != expected {
+			return SizeErr{Expected: expected, Actual: size}
+		}
 	}
+
+	withUnpack(0, 0, false, func() {
+		tex.Alloc(level, internalFormat, int32(width), int32(height), int32(depth), pf.Format(), pf.DataType(), pixels)
+	})
 	return nil
 }
 
-func (tex *Texture) AllocWithImage(img image.Image, level, internalFormat int32, format, dataType uint32) {
-	rgba := image.NewRGBA(img.Bounds())
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
-	size := img.Bounds().Size()
-	tex.Alloc(level, internalFormat, int32(size.X), int32(size.Y), 0, format, dataType, rgba.Pix)
+/*
+	Uploads pixels to a region of an allocated texture level after validating their size.
+
+	rowLength - the number of pixels from the start of one row to the next, width if 0
+	pixels - a slice of the component type or a byte slice
+*/
+func (tex *Texture) SubPixels(level int32, pf PixelFormat, x, y, z, width, height, depth, rowLength int, pixels interface{}) error {
+	if err := pf.Validate(); err != nil {
+		return err
+	}
+	if rowLength == 0 {
+		rowLength = width
+	}
+	if rowLength < width {
+		return FormatErr{Format: pf, Reason: "the row length is smaller than the width"}
+	}
+	size, err := pf.dataSize(pixels)
+	if err != nil {
+		return err
+	}
+	w, h, d := tex.extent(width, height, depth)
+	//The last row only has to contain width pixels
+	if expected := (((d-1)*h+h-1)*rowLength + w) * pf.PixelSize(); size < expected {
+		return SizeErr{Expected: expected, Actual: size}
+	}
+
+	withUnpack(rowLength, h, false, func() {
+		tex.SubImage(level, int32(x), int32(y), int32(z), int32(width), int32(height), int32(depth), pf.Format(), pf.DataType(), pixels)
+	})
+	return nil
+}
+
+//The dimensions used by the target, unused dimensions are 1
+func (tex *Texture) extent(width, height, depth int) (int, int, int) {
+	switch tex.Target {
+	case Texture1D, TextureProxy1D:
+		return width, 1, 1
+	case Texture3D, TextureProxy3D, Texture2DArray, TextureProxy2DArray:
+		return width, height, depth
+	}
+	return width, height, 1
+}
+
+/*
+	Sets the unpack parameters for unaligned rows, calls upload and restores the defaults.
+
+	rowLength - the number of pixels from the start of one row to the next, tightly packed if 0
+	imageHeight - the number of rows from the start of one image to the next, tightly packed if 0
+	swapBytes - swap the bytes of multi byte components
+*/
+func withUnpack(rowLength, imageHeight int, swapBytes bool, upload func()) {
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, int32(rowLength))
+	gl.PixelStorei(gl.UNPACK_IMAGE_HEIGHT, int32(imageHeight))
+	if swapBytes {
+		gl.PixelStorei(gl.UNPACK_SWAP_BYTES, gl.TRUE)
+	}
+
+	upload()
+
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+	gl.PixelStorei(gl.UNPACK_IMAGE_HEIGHT, 0)
+	if swapBytes {
+		gl.PixelStorei(gl.UNPACK_SWAP_BYTES, gl.FALSE)
+	}
+}
+
+//Reads a level of a bound 1D or 2D texture
+func (tex *Texture) ReadImage(level int32) (*image.RGBA, error) {
+	switch tex.Target {
+	case Texture3D, Texture2DArray, TextureProxy1D, TextureProxy2D, TextureProxy1DArray, TextureProxyRectangle, TextureCubeMap, TextureProxyCubeMap, TextureProxy3D, TextureProxy2DArray:
+		return nil, TargetErr{Target: tex.Target}
+	}
+
+	var width, height int32
+	gl.GetTexLevelParameteriv(uint32(tex.Target), level, gl.TEXTURE_WIDTH, &width)
+	gl.GetTexLevelParameteriv(uint32(tex.Target), level, gl.TEXTURE_HEIGHT, &height)
+	if width == 0 || height == 0 {
+		return nil, ErrNoImage
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 4)
+	gl.GetTexImage(uint32(tex.Target), level, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	if err := CheckGlError("glGetTexImage"); err != nil {
+		return nil, err
+	}
+	return img, nil
 }
 
-func (tex *Texture) AllocWithBytes(bytes []byte, width, height int32, level, internalFormat int32, format uint32) {
-	tex.Alloc(level, internalFormat, width, height, 0, format, gl.BYTE, bytes)
+func (tex *Texture) Destroy() {
+	tracker.Untrack(tracker.Texture, tex.Id())
+	gl.DeleteTextures(1, tex.uint32)
+	tex.uint32 = nil
 }
@@ -1,16 +1,21 @@
 package data_test
 
 import (
+	"image"
+	"image/color"
 	_ "image/png"
-	"os"
 	"testing"
 
+	"github.com/Qendolin/go-printpixel/internal/assets"
 	"github.com/Qendolin/go-printpixel/internal/canvas"
 	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/Qendolin/go-printpixel/internal/hdr"
 	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/Qendolin/go-printpixel/internal/tracker"
 	"github.com/Qendolin/go-printpixel/internal/utils"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMain(m *testing.M) {
@@ -22,11 +27,8 @@ func TestFileTexture(t *testing.T) {
 	win, close := test.NewWindow(t)
 	defer close()
 
-	absPath, err := utils.ResolvePath("assets/textures/uv.png")
-	if err != nil {
-		t.Fatal(err)
-	}
-	imgFile, err := os.Open(absPath)
+	src := assets.Dir(utils.MustResolveModulePath(""))
+	imgFile, err := src.Open("assets/textures/uv.png")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -36,7 +38,7 @@ func TestFileTexture(t *testing.T) {
 	tex.Bind(0)
 	tex.FilterMode(data.FilterLinear, data.FilterLinear)
 	tex.WrapMode(data.WrapClampToEdge, data.WrapClampToEdge, data.WrapClampToEdge)
-	err = tex.AllocWithFile2D(imgFile, 0, gl.RGBA, gl.RGBA, gl.UNSIGNED_BYTE)
+	err = tex.AllocWithFile2D(imgFile, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -74,7 +76,9 @@ func TestGeneratedTexture(t *testing.T) {
 		}
 	}
 
-	tex.AllocWithBytes(data, 100, 100, 0, gl.RGB, gl.RGB)
+	if err := tex.AllocWithBytes(data, 100, 100, 0, gl.RGB8, gl.RGB); err != nil {
+		t.Fatal(err)
+	}
 
 	prog := test.NewProgram(t, "assets/shaders/quad_tex.vert", "assets/shaders/quad_tex.frag")
 	cnv := canvas.NewCanvasWithProgram(prog)
@@ -88,3 +92,212 @@ func TestGeneratedTexture(t *testing.T) {
 		glfw.PollEvents()
 	}
 }
+
+func TestSubImageTexture(t *testing.T) {
+	win, close := test.NewWindow(t)
+	defer close()
+
+	tex := data.NewTexture(data.Texture2D)
+	tex.Bind(0)
+	tex.FilterMode(data.FilterNearest, data.FilterNearest)
+	tex.WrapMode(data.WrapClampToEdge, data.WrapClampToEdge, data.WrapClampToEdge)
+
+	data := make([]byte, 100*100*4)
+	tex.Alloc(0, gl.RGBA8, 100, 100, 0, gl.RGBA, gl.UNSIGNED_BYTE, data)
+
+	patch := make([]byte, 50*50*4)
+	for i := range patch {
+		patch[i] = 255
+	}
+	tex.SubImage(0, 25, 25, 0, 50, 50, 0, gl.RGBA, gl.UNSIGNED_BYTE, patch)
+
+	prog := test.NewProgram(t, "assets/shaders/quad_tex.vert", "assets/shaders/quad_tex.frag")
+	cnv := canvas.NewCanvasWithProgram(prog)
+
+	for !win.ShouldClose() {
+		cnv.BindFor(func() []func() {
+			cnv.Draw()
+			return nil
+		})
+		win.SwapBuffers()
+		glfw.PollEvents()
+	}
+}
+
+func TestReadImage(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	tex := data.NewTexture(data.Texture2D)
+	defer tex.Destroy()
+	tex.Bind(0)
+
+	pixels := make([]byte, 3*2*4)
+	for i := range pixels {
+		pixels[i] = byte(i * 10)
+	}
+	tex.Alloc(0, gl.RGBA8, 3, 2, 0, gl.RGBA, gl.UNSIGNED_BYTE, pixels)
+
+	img, err := tex.ReadImage(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, image.Rect(0, 0, 3, 2), img.Bounds())
+	assert.Equal(t, pixels, img.Pix)
+
+	_, err = tex.ReadImage(1)
+	assert.Equal(t, data.ErrNoImage, err)
+}
+
+func TestImageFormats(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	gray := image.NewGray(image.Rect(0, 0, 3, 3))
+	gray.SetGray(1, 1, color.Gray{100})
+
+	gray16 := image.NewGray16(image.Rect(0, 0, 3, 3))
+	gray16.SetGray16(1, 1, color.Gray16{0xAB00})
+
+	nrgba64 := image.NewNRGBA64(image.Rect(0, 0, 3, 3))
+	nrgba64.SetNRGBA64(1, 1, color.NRGBA64{0xFFFF, 0xAB00, 0, 0xFFFF})
+
+	paletted := image.NewPaletted(image.Rect(0, 0, 3, 3), color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{0, 255, 0, 255}})
+	paletted.SetColorIndex(1, 1, 1)
+
+	large := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	large.SetRGBA(5, 5, color.RGBA{1, 2, 3, 4})
+	sub := large.SubImage(image.Rect(4, 4, 7, 7))
+
+	float := hdr.NewImage(image.Rect(0, 0, 3, 3))
+	float.SetFloat(1, 1, 2, 0, 1)
+
+	cases := []struct {
+		name string
+		img  image.Image
+		want color.RGBA
+	}{
+		{"gray", gray, color.RGBA{100, 0, 0, 255}},
+		{"gray16", gray16, color.RGBA{170, 0, 0, 255}},
+		{"nrgba64", nrgba64, color.RGBA{255, 170, 0, 255}},
+		{"paletted", paletted, color.RGBA{0, 255, 0, 255}},
+		{"subimage", sub, color.RGBA{1, 2, 3, 4}},
+		{"hdr", float, color.RGBA{255, 0, 255, 255}},
+	}
+
+	for _, c := range cases {
+		tex := data.NewTexture(data.Texture2D)
+		tex.Bind(0)
+		tex.AllocWithImage(c.img, 0)
+		img, err := tex.ReadImage(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, image.Rect(0, 0, 3, 3), img.Bounds(), c.name)
+		assert.Equal(t, c.want, img.RGBAAt(1, 1), c.name)
+		tex.Destroy()
+	}
+}
+
+func TestImageSwizzle(t *testing.T) {
+	win, close := test.NewWindow(t)
+	defer close()
+
+	tex := data.NewTexture(data.Texture2D)
+	defer tex.Destroy()
+	tex.Bind(0)
+	tex.FilterMode(data.FilterNearest, data.FilterNearest)
+
+	prog := test.NewProgram(t, "assets/shaders/quad_tex.vert", "assets/shaders/quad_tex.frag")
+	cnv := canvas.NewCanvasWithProgram(prog)
+	defer cnv.Destroy()
+	cnv.Resize(win.GetFramebufferSize())
+	w, h := win.GetFramebufferSize()
+
+	snapshot := func() color.RGBA {
+		var img *image.RGBA
+		var err error
+		cnv.BindFor(func() []func() {
+			tex.Bind(0)
+			cnv.Draw()
+			img, err = cnv.Snapshot()
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img.RGBAAt(w/2, h/2)
+	}
+
+	gray := image.NewGray(image.Rect(0, 0, 1, 1))
+	gray.SetGray(0, 0, color.Gray{100})
+	tex.AllocWithImage(gray, 0)
+	assert.Equal(t, color.RGBA{100, 100, 100, 255}, snapshot())
+
+	//The gray swizzle must not be kept for color images
+	rgba := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	rgba.SetRGBA(0, 0, color.RGBA{10, 20, 30, 255})
+	tex.AllocWithImage(rgba, 0)
+	assert.Equal(t, color.RGBA{10, 20, 30, 255}, snapshot())
+}
+
+func TestSampler(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	smp := data.NewSampler()
+	defer smp.Destroy()
+	smp.WrapMode(data.WrapClampToBorder, data.WrapRepeat, 0)
+	smp.FilterMode(data.FilterNearest, data.FilterLinear)
+	smp.BorderColor(color.RGBA{255, 0, 0, 255})
+	smp.LodRange(0, 4)
+	smp.LodBias(0.5)
+	smp.CompareMode(true, data.CompareLess)
+	smp.Anisotropy(4)
+	smp.Bind(1)
+	defer smp.Unbind(1)
+	assert.NoError(t, data.CheckGlError("sampler parameters"))
+
+	var param int32
+	gl.GetSamplerParameteriv(smp.Id(), gl.TEXTURE_WRAP_S, &param)
+	assert.Equal(t, int32(gl.CLAMP_TO_BORDER), param)
+	gl.GetSamplerParameteriv(smp.Id(), gl.TEXTURE_MIN_FILTER, &param)
+	assert.Equal(t, int32(gl.NEAREST), param)
+	gl.GetSamplerParameteriv(smp.Id(), gl.TEXTURE_COMPARE_FUNC, &param)
+	assert.Equal(t, int32(gl.LESS), param)
+
+	border := make([]float32, 4)
+	gl.GetSamplerParameterfv(smp.Id(), gl.TEXTURE_BORDER_COLOR, &border[0])
+	assert.Equal(t, []float32{1, 0, 0, 1}, border)
+
+	var bias float32
+	gl.GetSamplerParameterfv(smp.Id(), gl.TEXTURE_LOD_BIAS, &bias)
+	assert.Equal(t, float32(0.5), bias)
+}
+
+func TestTrackResources(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	tracker.Enable()
+	defer tracker.Disable()
+	defer tracker.Reset()
+
+	tex := data.NewTexture(data.Texture2D)
+	tex.Bind(0)
+	tex.AllocWithImage(image.NewRGBA(image.Rect(0, 0, 4, 4)), 0)
+	tex.Alloc(1, gl.RGBA16F, 2, 2, 0, gl.RGBA, gl.FLOAT, nil)
+	vbo := data.NewVbo()
+	vbo.Bind(gl.ARRAY_BUFFER)
+	vbo.WriteStatic(make([]float32, 8))
+
+	assert.Equal(t, map[tracker.Kind]tracker.Total{
+		tracker.Texture: {Count: 1, Bytes: 4*4*4 + 2*2*8},
+		tracker.Buffer:  {Count: 1, Bytes: 32},
+	}, tracker.Totals())
+
+	tex.Destroy()
+	assert.Len(t, tracker.Leaks(), 1)
+	vbo.Destroy()
+	assert.Empty(t, tracker.Leaks())
+}
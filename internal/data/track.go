@@ -0,0 +1,53 @@
+package data
+
+import (
+	"github.com/Qendolin/go-printpixel/internal/tracker"
+)
+
+//The pixel sizes of the internal formats of all valid pixel formats
+var internalFormatSizes = func() map[int32]int {
+	sizes := map[int32]int{}
+	for channels := 1; channels <= 4; channels++ {
+		for componentType := range componentInfo {
+			for _, normalized := range []bool{false, true} {
+				for _, srgb := range []bool{false, true} {
+					pf := PixelFormat{Channels: channels, Type: componentType, Normalized: normalized, SRGB: srgb}
+					if internalFormat, err := pf.InternalFormat(); err == nil {
+						sizes[internalFormat] = pf.PixelSize()
+					}
+				}
+			}
+		}
+	}
+	return sizes
+}()
+
+//Estimates the pixel size from the internal format, or from the client format if the internal format is unsized
+func estimatePixelSize(internalFormat int32, format, dataType uint32) int {
+	if size, ok := internalFormatSizes[internalFormat]; ok {
+		return size
+	}
+	channels, ok := formatChannels[format]
+	if !ok {
+		channels = 4
+	}
+	for _, info := range componentInfo {
+		if info.dataType == dataType {
+			return channels * info.size
+		}
+	}
+	return channels
+}
+
+//Records the estimated size of a texture image with the tracker
+func (tex *Texture) trackImage(level int32, size int) {
+	switch tex.Target {
+	case TextureProxy1D, TextureProxy2D, TextureProxy1DArray, TextureProxyRectangle, TextureProxyCubeMap, TextureProxy3D, TextureProxy2DArray:
+		return
+	}
+	part := int(level) * 6
+	if tex.Target >= TextureCubeMapPositiveX && tex.Target <= TextureCubeMapNegativeZ {
+		part += int(tex.Target - TextureCubeMapPositiveX)
+	}
+	tracker.Resize(tracker.Texture, tex.Id(), part, size)
+}
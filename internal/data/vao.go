@@ -1,6 +1,7 @@
 package data
 
 import (
+	"github.com/Qendolin/go-printpixel/internal/tracker"
 	"github.com/Qendolin/go-printpixel/internal/utils"
 	"github.com/go-gl/gl/v3.3-core/gl"
 )
@@ -12,6 +13,7 @@ type Vao struct {
 func NewVao() *Vao {
 	id := new(uint32)
 	gl.GenVertexArrays(1, id)
+	tracker.Track(tracker.VertexArray, *id)
 	return &Vao{id}
 }
 
@@ -36,8 +38,9 @@ func (vao *Vao) BindFor(context utils.BindingClosure) {
 	}
 }
 
-//TODO: Destroy VBOs, note that VAOs can share VBOs
+//The VBOs are not destroyed, because VAOs can share them. Enable the tracker to find leaked VBOs.
 func (vao *Vao) Destroy() {
+	tracker.Untrack(tracker.VertexArray, vao.Id())
 	gl.DeleteVertexArrays(1, vao.uint32)
 	vao.uint32 = nil
 }
@@ -4,7 +4,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"reflect"
+	"unsafe"
 
+	"github.com/Qendolin/go-printpixel/internal/tracker"
 	"github.com/Qendolin/go-printpixel/internal/utils"
 	"github.com/go-gl/gl/v3.3-core/gl"
 )
@@ -24,6 +26,7 @@ type Vbo struct {
 func NewVbo() *Vbo {
 	id := new(uint32)
 	gl.GenBuffers(1, id)
+	tracker.Track(tracker.Buffer, *id)
 	return &Vbo{id}
 }
 
@@ -64,6 +67,36 @@ func (vbo *Vbo) Write(mode uint32, data interface{}) {
 		//Ignore, gl will throw error anyway
 	}
 	gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(data), mode)
+	tracker.Resize(tracker.Buffer, vbo.Id(), 0, size)
+}
+
+//Allocates uninitialized storage of size bytes for the buffer bound to target. Previous storage is orphaned.
+func (vbo *Vbo) Reserve(target uint32, size int, mode uint32) {
+	gl.BufferData(target, size, nil, mode)
+	tracker.Resize(tracker.Buffer, vbo.Id(), 0, size)
+}
+
+/*
+	Maps a range of the buffer bound to target into client memory.
+	The returned slice is only valid until Unmap is called, it is nil if the mapping failed.
+*/
+func (vbo *Vbo) MapRange(target uint32, offset, length int, access uint32) []byte {
+	ptr := gl.MapBufferRange(target, offset, length, access)
+	if ptr == nil {
+		return nil
+	}
+	//A slice header instead of an array conversion, which would limit the length
+	var mapped []byte
+	header := (*reflect.SliceHeader)(unsafe.Pointer(&mapped))
+	header.Data = uintptr(ptr)
+	header.Len = length
+	header.Cap = length
+	return mapped
+}
+
+//Returns false if the contents of the buffer were corrupted while it was mapped
+func (vbo *Vbo) Unmap(target uint32) bool {
+	return gl.UnmapBuffer(target)
 }
 
 func (vbo *Vbo) Layout(index int, size int, dataType interface{}, normalized bool, stride int) (err error) {
@@ -133,6 +166,7 @@ func getGlType(dataType reflect.Type) (glType uint32, float bool, err error) {
 }
 
 func (vbo *Vbo) Destroy() {
+	tracker.Untrack(tracker.Buffer, vbo.Id())
 	gl.DeleteBuffers(1, vbo.uint32)
 	vbo.uint32 = nil
 }
@@ -0,0 +1,294 @@
+//Package hdr decodes Radiance RGBE images (.hdr) into linear float images.
+//Importing it registers the format with image.Decode.
+package hdr
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+var (
+	ErrFormat  = errors.New("Not a Radiance HDR image")
+	ErrCorrupt = errors.New("The scanline data is corrupt")
+)
+
+//The header is untrusted, larger images are rejected before anything is allocated
+const (
+	maxDimension = 1 << 16
+	//3 floats per pixel still fit into a 32 bit int
+	maxPixels = 1 << 27
+)
+
+type ResolutionErr struct {
+	Resolution string
+}
+
+func (rerr ResolutionErr) Error() string {
+	return fmt.Sprintf("Unsupported resolution string '%v'", rerr.Resolution)
+}
+
+func init() {
+	image.RegisterFormat("hdr", "#?", Decode, DecodeConfig)
+}
+
+//An Image stores linear RGB floats, three per pixel. Values may exceed 1.
+type Image struct {
+	Pix []float32
+	//The number of floats between vertically adjacent pixels
+	Stride int
+	Rect   image.Rectangle
+}
+
+func NewImage(r image.Rectangle) *Image {
+	return &Image{
+		Pix:    make([]float32, 3*r.Dx()*r.Dy()),
+		Stride: 3 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+//Colors are clamped to [0, 1]
+func (img *Image) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (img *Image) Bounds() image.Rectangle {
+	return img.Rect
+}
+
+func (img *Image) At(x, y int) color.Color {
+	r, g, b := img.FloatAt(x, y)
+	return color.RGBA64{R: clamp16(r), G: clamp16(g), B: clamp16(b), A: 0xffff}
+}
+
+func clamp16(v float32) uint16 {
+	if v <= 0 || v != v {
+		return 0
+	}
+	if v >= 1 {
+		return 0xffff
+	}
+	return uint16(v*0xffff + 0.5)
+}
+
+func (img *Image) FloatAt(x, y int) (r, g, b float32) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+	i := img.PixOffset(x, y)
+	return img.Pix[i], img.Pix[i+1], img.Pix[i+2]
+}
+
+func (img *Image) SetFloat(x, y int, r, g, b float32) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+	i := img.PixOffset(x, y)
+	img.Pix[i], img.Pix[i+1], img.Pix[i+2] = r, g, b
+}
+
+//The index of the first float of the pixel at (x, y)
+func (img *Image) PixOffset(x, y int) int {
+	return (y-img.Rect.Min.Y)*img.Stride + (x-img.Rect.Min.X)*3
+}
+
+//Returns an image sharing the pixels of img
+func (img *Image) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(img.Rect)
+	if r.Empty() {
+		return &Image{}
+	}
+	return &Image{
+		Pix:    img.Pix[img.PixOffset(r.Min.X, r.Min.Y):],
+		Stride: img.Stride,
+		Rect:   r,
+	}
+}
+
+type header struct {
+	width, height int
+	//The file stores the rows bottom-up or the columns right to left
+	flipY, flipX bool
+}
+
+func readHeader(r *bufio.Reader) (h header, err error) {
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "#?") {
+		return h, ErrFormat
+	}
+
+	//Variables like FORMAT and EXPOSURE end at an empty line
+	for {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return h, ErrFormat
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "FORMAT=") && line != "FORMAT=32-bit_rle_rgbe" {
+			return h, fmt.Errorf("Unsupported pixel format '%v'", strings.TrimPrefix(line, "FORMAT="))
+		}
+	}
+
+	line, err = r.ReadString('\n')
+	if err != nil {
+		return h, ErrFormat
+	}
+	line = strings.TrimSpace(line)
+	var ySign, xSign string
+	if _, err := fmt.Sscanf(line, "%1sY %d %1sX %d", &ySign, &h.height, &xSign, &h.width); err != nil || h.width <= 0 || h.height <= 0 {
+		return h, ResolutionErr{Resolution: line}
+	}
+	if h.width > maxDimension || h.height > maxDimension || int64(h.width)*int64(h.height) > maxPixels {
+		return h, ResolutionErr{Resolution: line}
+	}
+	h.flipY = ySign == "+"
+	h.flipX = xSign == "-"
+	return h, nil
+}
+
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	h, err := readHeader(bufio.NewReader(r))
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.RGBA64Model, Width: h.width, Height: h.height}, nil
+}
+
+//Decodes an image with flat or run length encoded scanlines. The returned image is an *Image.
+func Decode(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	img := NewImage(image.Rect(0, 0, h.width, h.height))
+	scanline := make([]byte, 4*h.width)
+	for y := 0; y < h.height; y++ {
+		if err := readScanline(br, scanline); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				err = ErrCorrupt
+			}
+			return nil, err
+		}
+
+		row := y
+		if h.flipY {
+			row = h.height - 1 - y
+		}
+		for x := 0; x < h.width; x++ {
+			col := x
+			if h.flipX {
+				col = h.width - 1 - x
+			}
+			rgbe := scanline[x*4 : x*4+4]
+			i := img.PixOffset(col, row)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2] = decodeRgbe(rgbe[0], rgbe[1], rgbe[2], rgbe[3])
+		}
+	}
+	return img, nil
+}
+
+func decodeRgbe(r, g, b, e byte) (float32, float32, float32) {
+	if e == 0 {
+		return 0, 0, 0
+	}
+	f := float32(math.Ldexp(1, int(e)-(128+8)))
+	return float32(r) * f, float32(g) * f, float32(b) * f
+}
+
+//Reads one scanline of RGBE pixels into line
+func readScanline(r *bufio.Reader, line []byte) error {
+	width := len(line) / 4
+	start, err := r.Peek(4)
+	if err != nil {
+		return err
+	}
+	//The run length encoding stores each channel separately and is marked by two bytes of 2
+	if width < 8 || width > 0x7fff || start[0] != 2 || start[1] != 2 || start[2]&0x80 != 0 {
+		return readFlatScanline(r, line)
+	}
+	if int(start[2])<<8|int(start[3]) != width {
+		return ErrCorrupt
+	}
+	r.Discard(4)
+
+	for c := 0; c < 4; c++ {
+		for x := 0; x < width; {
+			count, err := r.ReadByte()
+			if err != nil {
+				return err
+			}
+			if count > 128 {
+				//A run of the same value
+				n := int(count) - 128
+				if x+n > width {
+					return ErrCorrupt
+				}
+				value, err := r.ReadByte()
+				if err != nil {
+					return err
+				}
+				for ; n > 0; n-- {
+					line[x*4+c] = value
+					x++
+				}
+			} else {
+				n := int(count)
+				if n == 0 || x+n > width {
+					return ErrCorrupt
+				}
+				for ; n > 0; n-- {
+					value, err := r.ReadByte()
+					if err != nil {
+						return err
+					}
+					line[x*4+c] = value
+					x++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//Reads uncompressed pixels, which may contain the old run length encoding of repeated pixels
+func readFlatScanline(r *bufio.Reader, line []byte) error {
+	width := len(line) / 4
+	shift := uint(0)
+	for x := 0; x < width; {
+		var pixel [4]byte
+		if _, err := io.ReadFull(r, pixel[:]); err != nil {
+			return err
+		}
+		if pixel[0] == 1 && pixel[1] == 1 && pixel[2] == 1 {
+			//Repeats the previous pixel, consecutive runs are more significant
+			if x == 0 {
+				return ErrCorrupt
+			}
+			n := int(pixel[3]) << shift
+			if x+n > width {
+				return ErrCorrupt
+			}
+			for ; n > 0; n-- {
+				copy(line[x*4:x*4+4], line[(x-1)*4:x*4])
+				x++
+			}
+			shift += 8
+			continue
+		}
+		copy(line[x*4:x*4+4], pixel[:])
+		x++
+		shift = 0
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package hdr_test
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/hdr"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	test.ParseArgs()
+	m.Run()
+}
+
+func header(resolution string) []byte {
+	return []byte("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\nEXPOSURE=1.0\n\n" + resolution + "\n")
+}
+
+func TestDecodeFlat(t *testing.T) {
+	file := header("-Y 2 +X 2")
+	file = append(file,
+		128, 64, 0, 129, //1, 0.5, 0
+		0, 0, 0, 0,
+		128, 128, 128, 132, //8, 8, 8
+		1, 1, 1, 1, //repeats the previous pixel once
+	)
+
+	img, format, err := image.Decode(bytes.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, "hdr", format)
+	hdrImg := img.(*hdr.Image)
+	assert.Equal(t, image.Rect(0, 0, 2, 2), hdrImg.Bounds())
+
+	r, g, b := hdrImg.FloatAt(0, 0)
+	assert.Equal(t, []float32{1, 0.5, 0}, []float32{r, g, b})
+	r, g, b = hdrImg.FloatAt(1, 0)
+	assert.Equal(t, []float32{0, 0, 0}, []float32{r, g, b})
+	r, g, b = hdrImg.FloatAt(1, 1)
+	assert.Equal(t, []float32{8, 8, 8}, []float32{r, g, b})
+
+	r16, _, _, _ := img.At(0, 1).RGBA()
+	assert.Equal(t, uint32(0xffff), r16)
+}
+
+func TestDecodeRle(t *testing.T) {
+	//A run for red, literal values for green, runs for blue and the exponent
+	file := header("+Y 1 +X 8")
+	file = append(file, 2, 2, 0, 8)
+	file = append(file, 128+8, 128)
+	file = append(file, 8, 0, 16, 32, 48, 64, 80, 96, 112)
+	file = append(file, 128+8, 0)
+	file = append(file, 128+4, 129, 128+4, 130)
+
+	img, err := hdr.Decode(bytes.NewReader(file))
+	assert.NoError(t, err)
+	hdrImg := img.(*hdr.Image)
+	r, g, b := hdrImg.FloatAt(1, 0)
+	assert.Equal(t, []float32{1, 0.125, 0}, []float32{r, g, b})
+	r, g, b = hdrImg.FloatAt(7, 0)
+	assert.Equal(t, []float32{2, 1.75, 0}, []float32{r, g, b})
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := hdr.Decode(bytes.NewReader([]byte("P6\n")))
+	assert.Equal(t, hdr.ErrFormat, err)
+
+	_, err = hdr.Decode(bytes.NewReader(header("+X 2 +Y 2")))
+	assert.Equal(t, hdr.ResolutionErr{Resolution: "+X 2 +Y 2"}, err)
+
+	//Rejected before the pixels are allocated
+	_, err = hdr.Decode(bytes.NewReader(header("-Y 100000 +X 100000")))
+	assert.Equal(t, hdr.ResolutionErr{Resolution: "-Y 100000 +X 100000"}, err)
+	_, err = hdr.Decode(bytes.NewReader(header("-Y 65536 +X 65536")))
+	assert.Equal(t, hdr.ResolutionErr{Resolution: "-Y 65536 +X 65536"}, err)
+	_, err = hdr.Decode(bytes.NewReader(header("-Y 1 +X 99999999999999999999")))
+	assert.IsType(t, hdr.ResolutionErr{}, err)
+
+	_, err = hdr.Decode(bytes.NewReader(append(header("-Y 1 +X 2"), 1, 2, 3)))
+	assert.Equal(t, hdr.ErrCorrupt, err)
+
+	cfg, err := hdr.DecodeConfig(bytes.NewReader(header("-Y 3 +X 5")))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cfg.Width)
+	assert.Equal(t, 3, cfg.Height)
+}
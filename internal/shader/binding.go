@@ -0,0 +1,214 @@
+package shader
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrNotStruct = errors.New("Uniforms can only be set from a struct or a pointer to a struct")
+
+//Reported by SetUniforms, the matching uniforms are set regardless
+type BindingErr struct {
+	//The Go paths of fields without an active uniform, e.g. "Lights[1].Color"
+	Fields []string
+	//Active uniforms of the default block without a field
+	Uniforms []string
+	//The UniformTypeErr and UniformSizeErr of fields with a matching uniform
+	Errs []error
+}
+
+func (berr BindingErr) Error() string {
+	var parts []string
+	if len(berr.Fields) > 0 {
+		parts = append(parts, fmt.Sprintf("fields without uniform: %v", strings.Join(berr.Fields, ", ")))
+	}
+	if len(berr.Uniforms) > 0 {
+		parts = append(parts, fmt.Sprintf("uniforms without field: %v", strings.Join(berr.Uniforms, ", ")))
+	}
+	for _, err := range berr.Errs {
+		parts = append(parts, err.Error())
+	}
+	return "Failed to bind uniforms, " + strings.Join(parts, "; ")
+}
+
+//How the tagged fields of a struct type map to uniforms
+type bindingPlan []fieldBinding
+
+type fieldBinding struct {
+	index []int
+	//The Go field name and the uniform name, relative to the parent
+	field   string
+	uniform string
+	//Set for structs and arrays or slices of structs, which are bound per member
+	members bindingPlan
+	array   bool
+}
+
+var bindingPlans sync.Map
+
+func planFor(t reflect.Type) bindingPlan {
+	if plan, ok := bindingPlans.Load(t); ok {
+		return plan.(bindingPlan)
+	}
+	plan := newBindingPlan(t, nil, make(map[reflect.Type]bool))
+	bindingPlans.Store(t, plan)
+	return plan
+}
+
+/*
+	Collects the fields with a `uniform:"name"` tag. Untagged embedded structs are flattened.
+
+	index - the index path of t in the outermost struct, for embedded structs
+	planning - the struct types that are being planned, fields of these types are not planned again to end recursion
+*/
+func newBindingPlan(t reflect.Type, index []int, planning map[reflect.Type]bool) bindingPlan {
+	planning[t] = true
+	defer delete(planning, t)
+
+	var plan bindingPlan
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		name, tagged := field.Tag.Lookup("uniform")
+		//Only the exported fields of unexported embedded structs can be read
+		if name == "-" || field.PkgPath != "" && (tagged || !field.Anonymous) {
+			continue
+		}
+		if !tagged {
+			if embedded := derefType(field.Type); field.Anonymous && embedded.Kind() == reflect.Struct && !planning[embedded] {
+				plan = append(plan, newBindingPlan(embedded, fieldIndex, planning)...)
+			}
+			continue
+		}
+
+		binding := fieldBinding{index: fieldIndex, field: field.Name, uniform: name}
+		elem := derefType(field.Type)
+		if kind := elem.Kind(); kind == reflect.Array || kind == reflect.Slice {
+			if _, ok := shapeOf(elem); !ok {
+				binding.array = true
+				elem = derefType(elem.Elem())
+			}
+		}
+		//Recursive fields have no members, setting them reports the field as unmatched
+		if _, ok := shapeOf(elem); !ok && elem.Kind() == reflect.Struct && !planning[elem] {
+			binding.members = newBindingPlan(elem, nil, planning)
+		}
+		plan = append(plan, binding)
+	}
+	return plan
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+//Dereferences pointers, returns false for nil
+func derefValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+//Like reflect.Value.FieldByIndex but returns false instead of panicking for nil embedded structs
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		var ok bool
+		if v, ok = derefValue(v); !ok {
+			return v, false
+		}
+		v = v.Field(i)
+	}
+	return derefValue(v)
+}
+
+/*
+	Sets the uniforms from the tagged fields of a struct, the program has to be bound.
+
+	Fields are tagged with the uniform name, e.g. `uniform:"u_color"`. Fields of nested structs are named
+	relative to their parent, so a field tagged "tint" in a field tagged "u_material" sets "u_material.tint".
+	Arrays and slices of structs set "name[i].member", other arrays and slices set the uniform array.
+	Nil pointers are skipped. Returns a BindingErr if fields or uniforms are unmatched.
+	Fields of a struct type that contains them, e.g. Next *Node in Node, are not expanded and can't be matched.
+*/
+func (prog *Program) SetUniforms(v interface{}) error {
+	value, ok := derefValue(reflect.ValueOf(v))
+	if !ok || value.Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+
+	b := binder{prog: prog, set: make(map[string]bool)}
+	b.bind(planFor(value.Type()), value, "", "")
+
+	for _, info := range prog.Uniforms() {
+		if info.Block == -1 && !b.set[info.Name] {
+			b.err.Uniforms = append(b.err.Uniforms, info.Name)
+		}
+	}
+	if len(b.err.Fields) == 0 && len(b.err.Uniforms) == 0 && len(b.err.Errs) == 0 {
+		return nil
+	}
+	return b.err
+}
+
+type binder struct {
+	prog *Program
+	//The uniforms that were matched
+	set map[string]bool
+	err BindingErr
+}
+
+func (b *binder) bind(plan bindingPlan, value reflect.Value, fieldPrefix, uniformPrefix string) {
+	for _, binding := range plan {
+		field, ok := fieldByIndex(value, binding.index)
+		if !ok {
+			continue
+		}
+		fieldPath := fieldPrefix + binding.field
+		uniform := uniformPrefix + binding.uniform
+
+		switch {
+		case binding.array && binding.members != nil:
+			for i := 0; i < field.Len(); i++ {
+				elem, ok := derefValue(field.Index(i))
+				if !ok {
+					continue
+				}
+				index := "[" + strconv.Itoa(i) + "]"
+				b.bind(binding.members, elem, fieldPath+index+".", uniform+index+".")
+			}
+		case binding.members != nil:
+			b.bind(binding.members, field, fieldPath+".", uniform+".")
+		case binding.array && field.Kind() == reflect.Array:
+			//Set only accepts slices for arrays of vectors and matrices
+			slice := reflect.MakeSlice(reflect.SliceOf(field.Type().Elem()), field.Len(), field.Len())
+			reflect.Copy(slice, field)
+			b.setUniform(fieldPath, uniform, slice)
+		default:
+			b.setUniform(fieldPath, uniform, field)
+		}
+	}
+}
+
+func (b *binder) setUniform(fieldPath, uniform string, value reflect.Value) {
+	err := b.prog.SetUniform(uniform, value.Interface())
+	switch err.(type) {
+	case nil:
+		b.set[uniform] = true
+	case UniformLinkError:
+		b.err.Fields = append(b.err.Fields, fieldPath)
+	default:
+		b.set[uniform] = true
+		b.err.Errs = append(b.err.Errs, err)
+	}
+}
@@ -0,0 +1,126 @@
+package shader_test
+
+import (
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/shader"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+const bindingFrag = `#version 330 core
+out vec4 out_color;
+
+struct Light {
+	vec3 color;
+	float intensity;
+};
+
+uniform sampler2D u_tex;
+uniform float u_weights[4];
+uniform vec4 u_colors[2];
+uniform Light u_lights[2];
+uniform Light u_sun;
+uniform int u_mode;
+
+void main() {
+	vec3 light = u_lights[0].color * u_lights[0].intensity + u_lights[1].color * u_lights[1].intensity;
+	light += u_sun.color * u_sun.intensity;
+	out_color = texture(u_tex, vec2(0.)) * vec4(light, 1.) * u_weights[3] + u_colors[1] * float(u_mode);
+}`
+
+type light struct {
+	Color     mgl32.Vec3 `uniform:"color"`
+	Intensity float32    `uniform:"intensity"`
+}
+
+type common struct {
+	Transform mgl32.Mat4 `uniform:"u_transform"`
+}
+
+type scene struct {
+	common
+	Weights  []float32        `uniform:"u_weights"`
+	Colors   [2]mgl32.Vec4    `uniform:"u_colors"`
+	Lights   []light          `uniform:"u_lights"`
+	Sun      *light           `uniform:"u_sun"`
+	Mode     int32            `uniform:"u_mode"`
+	Missing  float32          `uniform:"u_missing"`
+	Skipped  mgl32.Vec2       `uniform:"-"`
+	Untagged map[string]int32 //Not a uniform
+}
+
+//Recursive through a tagged field and through an embedded struct
+type node struct {
+	Mode int32 `uniform:"u_mode"`
+	Next *node `uniform:"u_next"`
+}
+
+type looped struct {
+	*looped
+	Mode int32 `uniform:"u_mode"`
+}
+
+func TestSetUniformsNotStruct(t *testing.T) {
+	prog := &shader.Program{}
+	assert.Equal(t, shader.ErrNotStruct, prog.SetUniforms(1))
+	assert.Equal(t, shader.ErrNotStruct, prog.SetUniforms((*scene)(nil)))
+}
+
+func TestSetUniforms(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	prog := newProgram(t, introspectVert, bindingFrag)
+	defer prog.Destroy()
+	prog.Bind()
+
+	s := scene{
+		common:  common{Transform: mgl32.Ident4()},
+		Weights: []float32{1, 2, 3, 4},
+		Colors:  [2]mgl32.Vec4{{1, 0, 0, 1}, {0, 1, 0, 1}},
+		Lights:  []light{{Color: mgl32.Vec3{1, 1, 1}, Intensity: 2}},
+		Sun:     &light{Color: mgl32.Vec3{1, 1, 0}, Intensity: 5},
+		Mode:    2,
+	}
+	err := prog.SetUniforms(&s)
+	if assert.IsType(t, shader.BindingErr{}, err) {
+		berr := err.(shader.BindingErr)
+		assert.Equal(t, []string{"Missing"}, berr.Fields)
+		assert.ElementsMatch(t, []string{"u_tex", "u_lights[1].color", "u_lights[1].intensity"}, berr.Uniforms)
+		assert.Empty(t, berr.Errs)
+	}
+
+	uni, _ := prog.Uniform("u_sun.intensity")
+	var intensity float32
+	gl.GetUniformfv(prog.Id(), uni.Location(), &intensity)
+	assert.Equal(t, float32(5), intensity)
+
+	uni, _ = prog.Uniform("u_colors[1]")
+	var color mgl32.Vec4
+	gl.GetUniformfv(prog.Id(), uni.Location(), &color[0])
+	assert.Equal(t, mgl32.Vec4{0, 1, 0, 1}, color)
+
+	//A type mismatch is reported, the other uniforms are set
+	type wrong struct {
+		Mode float32 `uniform:"u_mode"`
+	}
+	err = prog.SetUniforms(wrong{})
+	if assert.IsType(t, shader.BindingErr{}, err) {
+		errs := err.(shader.BindingErr).Errs
+		assert.Len(t, errs, 1)
+		assert.IsType(t, shader.UniformTypeErr{}, errs[0])
+	}
+
+	//Recursive types are planned once, so the recursive field can't be matched
+	err = prog.SetUniforms(&node{Mode: 1, Next: &node{}})
+	if assert.IsType(t, shader.BindingErr{}, err) {
+		assert.Equal(t, []string{"Next"}, err.(shader.BindingErr).Fields)
+	}
+	err = prog.SetUniforms(looped{looped: &looped{}, Mode: 1})
+	if assert.IsType(t, shader.BindingErr{}, err) {
+		assert.Empty(t, err.(shader.BindingErr).Fields)
+	}
+}
@@ -0,0 +1,196 @@
+package shader
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityError = Severity(iota)
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+//Only the last word is used, e.g. "preprocessor error" is an error
+func parseSeverity(s string) Severity {
+	s = strings.ToLower(s[strings.LastIndexByte(s, ' ')+1:])
+	switch {
+	case strings.HasPrefix(s, "error"):
+		return SeverityError
+	case strings.HasPrefix(s, "warn"):
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
+
+//A message of a compiler or linker log
+type Diagnostic struct {
+	//Empty if the source was not preprocessed or the line was injected
+	File string
+	//1 based, 0 if the message has no location
+	Line int
+	//1 based, 0 if the compiler does not report it
+	Column   int
+	Severity Severity
+	Message  string
+	//The text of the line, empty if unknown
+	Source string
+}
+
+//Formats the location, severity and message like "lib.glsl:12:5: error: message"
+func (d Diagnostic) String() string {
+	var location string
+	if d.File != "" {
+		location = d.File + ":"
+	}
+	if d.Line > 0 {
+		location += strconv.Itoa(d.Line) + ":"
+	}
+	if d.Column > 0 {
+		location += strconv.Itoa(d.Column) + ":"
+	}
+	if location != "" {
+		location += " "
+	}
+	return fmt.Sprintf("%v%v: %v", location, d.Severity, d.Message)
+}
+
+//Like String, followed by the source line and a caret below the column or the start of the line
+func (d Diagnostic) Render() string {
+	if strings.TrimSpace(d.Source) == "" {
+		return d.String()
+	}
+	source := strings.TrimRight(d.Source, " \t")
+	column := d.Column - 1
+	if column < 0 || column > len(source) {
+		column = len(source) - len(strings.TrimLeft(source, " \t"))
+	}
+	//Keep the tabs so the caret lines up
+	indent := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, source[:column])
+	return fmt.Sprintf("%v\n    %v\n    %v^", d.String(), source, indent)
+}
+
+var (
+	//0:12(5): error: message or 0:12(5): preprocessor error: message
+	mesaLog = regexp.MustCompile(`^\d+:(\d+)\((\d+)\): (\w+(?: \w+)?): (.*)$`)
+	//0(12) : error C0000: message
+	nvidiaLog = regexp.MustCompile(`^\d+\((\d+)\) : (\w+)(?: \w+)?: (.*)$`)
+	//ERROR: 0:12: message
+	amdLog = regexp.MustCompile(`^(ERROR|WARNING|INFO): \d+:(\d+): (.*)$`)
+	//error: message, as written by linkers
+	plainLog = regexp.MustCompile(`^(?i)(error|warning|info): (.*)$`)
+	//ERROR: 1 compilation errors.  No code generated.
+	summaryLog = regexp.MustCompile(`^\d+ compilation errors?\.`)
+)
+
+/*
+	Parses the Mesa, NVIDIA and AMD log dialects. Lines that are not understood are skipped.
+
+	source - the compiled source, used to fill in Diagnostic.Source
+	lines - maps the lines of source to their origin, may be nil
+*/
+func ParseLog(log string, source string, lines LineMap) []Diagnostic {
+	var sourceLines []string
+	if source != "" {
+		sourceLines = strings.Split(source, "\n")
+	}
+
+	var diags []Diagnostic
+	for _, line := range splitLog(log) {
+		d, ok := parseLogLine(line)
+		if !ok {
+			continue
+		}
+
+		if d.Line > 0 && d.Line <= len(sourceLines) {
+			d.Source = strings.TrimRight(sourceLines[d.Line-1], "\r")
+		}
+		if lines != nil && d.Line > 0 {
+			if origin, ok := lines.Lookup(d.Line); ok {
+				d.File, d.Line = origin.File, origin.Line
+			}
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+//The trimmed lines of the log without empty lines
+func splitLog(log string) []string {
+	var lines []string
+	for _, line := range strings.Split(log, "\n") {
+		if line = strings.TrimSpace(strings.TrimRight(line, "\x00")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+//Returns false for lines that are not understood, the location is relative to the compiled source
+func parseLogLine(line string) (d Diagnostic, ok bool) {
+	if m := mesaLog.FindStringSubmatch(line); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+		d.Column, _ = strconv.Atoi(m[2])
+		d.Severity = parseSeverity(m[3])
+		d.Message = m[4]
+	} else if m := nvidiaLog.FindStringSubmatch(line); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+		d.Severity = parseSeverity(m[2])
+		d.Message = m[3]
+	} else if m := amdLog.FindStringSubmatch(line); m != nil {
+		d.Severity = parseSeverity(m[1])
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Message = m[3]
+	} else if m := plainLog.FindStringSubmatch(line); m != nil && !summaryLog.MatchString(m[2]) {
+		d.Severity = parseSeverity(m[1])
+		d.Message = m[2]
+	} else {
+		return d, false
+	}
+	return d, true
+}
+
+//Renders the diagnostics followed by the lines of the log that were not parsed, or returns the log if there are none
+func formatDiagnostics(diags []Diagnostic, log string) string {
+	if len(diags) == 0 {
+		return log
+	}
+	var rendered []string
+	for _, d := range diags {
+		rendered = append(rendered, d.Render())
+	}
+	//Continuation lines of multi-line messages and notes
+	for _, line := range splitLog(log) {
+		if _, ok := parseLogLine(line); !ok && !isSummary(line) {
+			rendered = append(rendered, line)
+		}
+	}
+	return strings.Join(rendered, "\n")
+}
+
+//Summaries like "ERROR: 1 compilation errors.  No code generated." repeat the diagnostics
+func isSummary(line string) bool {
+	m := plainLog.FindStringSubmatch(line)
+	return m != nil && summaryLog.MatchString(m[2])
+}
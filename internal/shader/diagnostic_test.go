@@ -0,0 +1,57 @@
+package shader_test
+
+import (
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/shader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLog(t *testing.T) {
+	source := "#version 330 core\n#define X\nvoid main() {\n\tfloat y = x;\n}\n"
+	lines := shader.LineMap{{File: "main.frag", Line: 1}, {}, {File: "main.frag", Line: 2}, {File: "lib.glsl", Line: 7}}
+
+	logs := map[string]shader.Diagnostic{
+		"mesa":   {File: "lib.glsl", Line: 7, Column: 12, Severity: shader.SeverityError, Message: "`x' undeclared", Source: "\tfloat y = x;"},
+		"nvidia": {File: "lib.glsl", Line: 7, Severity: shader.SeverityError, Message: "undefined variable \"x\"", Source: "\tfloat y = x;"},
+		"amd":    {File: "lib.glsl", Line: 7, Severity: shader.SeverityError, Message: "'x' : undeclared identifier", Source: "\tfloat y = x;"},
+	}
+	diags := map[string][]shader.Diagnostic{
+		"mesa":   shader.ParseLog("0:4(12): error: `x' undeclared\n\x00", source, lines),
+		"nvidia": shader.ParseLog("0(4) : error C1008: undefined variable \"x\"\n", source, lines),
+		"amd":    shader.ParseLog("ERROR: 0:4: 'x' : undeclared identifier\nERROR: 1 compilation errors.  No code generated.\n\n", source, lines),
+	}
+	for dialect, expected := range logs {
+		assert.Equal(t, []shader.Diagnostic{expected}, diags[dialect], dialect)
+	}
+
+	diag := shader.ParseLog("0:2(1): warning: injected", source, lines)
+	assert.Equal(t, []shader.Diagnostic{{Line: 2, Column: 1, Severity: shader.SeverityWarning, Message: "injected", Source: "#define X"}}, diag)
+
+	diag = shader.ParseLog("0:3(1): preprocessor error: Invalid tokens after #", source, nil)
+	assert.Equal(t, []shader.Diagnostic{{Line: 3, Column: 1, Severity: shader.SeverityError, Message: "Invalid tokens after #", Source: "void main() {"}}, diag)
+
+	diag = shader.ParseLog("error: vertex shader output `pass_uv' not written\nsome other text", "", nil)
+	assert.Equal(t, []shader.Diagnostic{{Severity: shader.SeverityError, Message: "vertex shader output `pass_uv' not written"}}, diag)
+}
+
+func TestDiagnosticRender(t *testing.T) {
+	d := shader.Diagnostic{File: "lib.glsl", Line: 7, Column: 12, Severity: shader.SeverityError, Message: "`x' undeclared", Source: "\tfloat y = x;"}
+	assert.Equal(t, "lib.glsl:7:12: error: `x' undeclared\n    \tfloat y = x;\n    \t          ^", d.Render())
+
+	d.Column = 0
+	assert.Equal(t, "lib.glsl:7: error: `x' undeclared\n    \tfloat y = x;\n    \t^", d.Render())
+
+	d = shader.Diagnostic{Severity: shader.SeverityWarning, Message: "unused"}
+	assert.Equal(t, "warning: unused", d.Render())
+
+	err := shader.CompileErr{Log: "raw log", Diagnostics: []shader.Diagnostic{d}}
+	assert.Contains(t, err.Error(), "warning: unused")
+	assert.Contains(t, shader.CompileErr{Log: "raw log"}.Error(), "raw log")
+
+	//Lines that are not parsed follow the diagnostics, summaries are dropped
+	log := "0:1(1): error: first\n  continued on the next line\nERROR: 1 compilation errors.  No code generated.\n"
+	err = shader.CompileErr{Log: log, Diagnostics: shader.ParseLog(log, "", nil)}
+	assert.Contains(t, err.Error(), "1:1: error: first\ncontinued on the next line\n")
+	assert.NotContains(t, err.Error(), "compilation errors")
+}
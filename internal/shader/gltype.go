@@ -0,0 +1,106 @@
+package shader
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+//The type of an active uniform or attribute, e.g. gl.FLOAT_VEC3
+type GlType uint32
+
+type glTypeInfo struct {
+	//The GLSL name
+	name string
+	//The component type, gl.FLOAT, gl.DOUBLE, gl.INT, gl.UNSIGNED_INT or gl.BOOL. Samplers are gl.INT
+	component uint32
+	//1 for scalars and vectors
+	columns int
+	//The components of a vector or a matrix column
+	rows    int
+	sampler bool
+}
+
+var glTypes = map[GlType]glTypeInfo{
+	gl.FLOAT:             {"float", gl.FLOAT, 1, 1, false},
+	gl.FLOAT_VEC2:        {"vec2", gl.FLOAT, 1, 2, false},
+	gl.FLOAT_VEC3:        {"vec3", gl.FLOAT, 1, 3, false},
+	gl.FLOAT_VEC4:        {"vec4", gl.FLOAT, 1, 4, false},
+	gl.DOUBLE:            {"double", gl.DOUBLE, 1, 1, false},
+	gl.DOUBLE_VEC2:       {"dvec2", gl.DOUBLE, 1, 2, false},
+	gl.DOUBLE_VEC3:       {"dvec3", gl.DOUBLE, 1, 3, false},
+	gl.DOUBLE_VEC4:       {"dvec4", gl.DOUBLE, 1, 4, false},
+	gl.INT:               {"int", gl.INT, 1, 1, false},
+	gl.INT_VEC2:          {"ivec2", gl.INT, 1, 2, false},
+	gl.INT_VEC3:          {"ivec3", gl.INT, 1, 3, false},
+	gl.INT_VEC4:          {"ivec4", gl.INT, 1, 4, false},
+	gl.UNSIGNED_INT:      {"uint", gl.UNSIGNED_INT, 1, 1, false},
+	gl.UNSIGNED_INT_VEC2: {"uvec2", gl.UNSIGNED_INT, 1, 2, false},
+	gl.UNSIGNED_INT_VEC3: {"uvec3", gl.UNSIGNED_INT, 1, 3, false},
+	gl.UNSIGNED_INT_VEC4: {"uvec4", gl.UNSIGNED_INT, 1, 4, false},
+	gl.BOOL:              {"bool", gl.BOOL, 1, 1, false},
+	gl.BOOL_VEC2:         {"bvec2", gl.BOOL, 1, 2, false},
+	gl.BOOL_VEC3:         {"bvec3", gl.BOOL, 1, 3, false},
+	gl.BOOL_VEC4:         {"bvec4", gl.BOOL, 1, 4, false},
+	gl.FLOAT_MAT2:        {"mat2", gl.FLOAT, 2, 2, false},
+	gl.FLOAT_MAT3:        {"mat3", gl.FLOAT, 3, 3, false},
+	gl.FLOAT_MAT4:        {"mat4", gl.FLOAT, 4, 4, false},
+	gl.FLOAT_MAT2x3:      {"mat2x3", gl.FLOAT, 2, 3, false},
+	gl.FLOAT_MAT2x4:      {"mat2x4", gl.FLOAT, 2, 4, false},
+	gl.FLOAT_MAT3x2:      {"mat3x2", gl.FLOAT, 3, 2, false},
+	gl.FLOAT_MAT3x4:      {"mat3x4", gl.FLOAT, 3, 4, false},
+	gl.FLOAT_MAT4x2:      {"mat4x2", gl.FLOAT, 4, 2, false},
+	gl.FLOAT_MAT4x3:      {"mat4x3", gl.FLOAT, 4, 3, false},
+	gl.DOUBLE_MAT2:       {"dmat2", gl.DOUBLE, 2, 2, false},
+	gl.DOUBLE_MAT3:       {"dmat3", gl.DOUBLE, 3, 3, false},
+	gl.DOUBLE_MAT4:       {"dmat4", gl.DOUBLE, 4, 4, false},
+
+	gl.SAMPLER_1D:                                {"sampler1D", gl.INT, 1, 1, true},
+	gl.SAMPLER_2D:                                {"sampler2D", gl.INT, 1, 1, true},
+	gl.SAMPLER_3D:                                {"sampler3D", gl.INT, 1, 1, true},
+	gl.SAMPLER_CUBE:                              {"samplerCube", gl.INT, 1, 1, true},
+	gl.SAMPLER_1D_SHADOW:                         {"sampler1DShadow", gl.INT, 1, 1, true},
+	gl.SAMPLER_2D_SHADOW:                         {"sampler2DShadow", gl.INT, 1, 1, true},
+	gl.SAMPLER_1D_ARRAY:                          {"sampler1DArray", gl.INT, 1, 1, true},
+	gl.SAMPLER_2D_ARRAY:                          {"sampler2DArray", gl.INT, 1, 1, true},
+	gl.SAMPLER_1D_ARRAY_SHADOW:                   {"sampler1DArrayShadow", gl.INT, 1, 1, true},
+	gl.SAMPLER_2D_ARRAY_SHADOW:                   {"sampler2DArrayShadow", gl.INT, 1, 1, true},
+	gl.SAMPLER_2D_MULTISAMPLE:                    {"sampler2DMS", gl.INT, 1, 1, true},
+	gl.SAMPLER_2D_MULTISAMPLE_ARRAY:              {"sampler2DMSArray", gl.INT, 1, 1, true},
+	gl.SAMPLER_CUBE_SHADOW:                       {"samplerCubeShadow", gl.INT, 1, 1, true},
+	gl.SAMPLER_BUFFER:                            {"samplerBuffer", gl.INT, 1, 1, true},
+	gl.SAMPLER_2D_RECT:                           {"sampler2DRect", gl.INT, 1, 1, true},
+	gl.SAMPLER_2D_RECT_SHADOW:                    {"sampler2DRectShadow", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_1D:                            {"isampler1D", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_2D:                            {"isampler2D", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_3D:                            {"isampler3D", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_CUBE:                          {"isamplerCube", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_1D_ARRAY:                      {"isampler1DArray", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_2D_ARRAY:                      {"isampler2DArray", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_2D_MULTISAMPLE:                {"isampler2DMS", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_2D_MULTISAMPLE_ARRAY:          {"isampler2DMSArray", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_BUFFER:                        {"isamplerBuffer", gl.INT, 1, 1, true},
+	gl.INT_SAMPLER_2D_RECT:                       {"isampler2DRect", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_1D:                   {"usampler1D", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_2D:                   {"usampler2D", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_3D:                   {"usampler3D", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_CUBE:                 {"usamplerCube", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_1D_ARRAY:             {"usampler1DArray", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_2D_ARRAY:             {"usampler2DArray", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_2D_MULTISAMPLE:       {"usampler2DMS", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_2D_MULTISAMPLE_ARRAY: {"usampler2DMSArray", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_BUFFER:               {"usamplerBuffer", gl.INT, 1, 1, true},
+	gl.UNSIGNED_INT_SAMPLER_2D_RECT:              {"usampler2DRect", gl.INT, 1, 1, true},
+}
+
+//The GLSL name, e.g. "vec3"
+func (t GlType) String() string {
+	if info, ok := glTypes[t]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("GlType(0x%04X)", uint32(t))
+}
+
+func (t GlType) IsSampler() bool {
+	return glTypes[t].sampler
+}
@@ -0,0 +1,215 @@
+package shader
+
+import (
+	"strings"
+
+	"github.com/Qendolin/go-printpixel/internal/utils"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+//An active uniform of a program
+type UniformInfo struct {
+	//Arrays are named without the "[0]" suffix, members of struct arrays are listed separately, e.g. "u_lights[1].color"
+	Name string
+	Type GlType
+	//The array length, 1 if the uniform is not an array
+	Size int
+	//-1 for uniforms of a uniform block
+	Location int32
+	//The index of the uniform block, -1 for the default block
+	Block int
+	//The byte offset in the uniform block, -1 for the default block
+	Offset int
+}
+
+//An active vertex attribute of a program
+type AttributeInfo struct {
+	Name string
+	Type GlType
+	Size int
+	//-1 for built in attributes, e.g. gl_VertexID
+	Location int32
+}
+
+type UniformBlockInfo struct {
+	Name    string
+	Index   uint32
+	Binding uint32
+	//The minimum size of the buffer in bytes
+	DataSize int
+	//The names of the uniforms in the block, as in UniformInfo
+	Members []string
+}
+
+//The introspection results and uniforms by name, shared by all copies of a Program
+type programCache struct {
+	uniforms      map[string]*Uniform
+	uniformInfos  []UniformInfo
+	attributes    []AttributeInfo
+	uniformBlocks []UniformBlockInfo
+}
+
+func newProgramCache() *programCache {
+	return &programCache{uniforms: make(map[string]*Uniform)}
+}
+
+//Clears the introspection results and locates the uniforms in the new program
+func (prog *Program) resetCache() {
+	cache := prog.getCache()
+	cache.uniformInfos = nil
+	cache.attributes = nil
+	cache.uniformBlocks = nil
+	for name, uni := range cache.uniforms {
+		*uni.int32 = gl.GetUniformLocation(prog.Id(), gl.Str(utils.NullTerm(name)))
+		uni.meta.glType, uni.meta.size = prog.uniformType(name)
+	}
+}
+
+//Programs that were not created by NewProgram get a cache that is not kept
+func (prog *Program) getCache() *programCache {
+	if prog.cache == nil {
+		return newProgramCache()
+	}
+	return prog.cache
+}
+
+//Returns the uniform with that name, which is looked up only once
+func (prog *Program) Uniform(name string) (*Uniform, error) {
+	cache := prog.getCache()
+	if uni, ok := cache.uniforms[name]; ok {
+		return uni, nil
+	}
+	uni, err := NewUniform(*prog, name)
+	if err != nil {
+		return nil, err
+	}
+	cache.uniforms[name] = uni
+	return uni, nil
+}
+
+//Sets a uniform by name, the program has to be bound
+func (prog *Program) SetUniform(name string, value interface{}) error {
+	uni, err := prog.Uniform(name)
+	if err != nil {
+		return err
+	}
+	return uni.Set(value)
+}
+
+//The active uniforms, as reported by glGetActiveUniform
+func (prog *Program) Uniforms() []UniformInfo {
+	cache := prog.getCache()
+	if cache.uniformInfos != nil {
+		return cache.uniformInfos
+	}
+
+	id := prog.Id()
+	var count, maxLength int32
+	gl.GetProgramiv(id, gl.ACTIVE_UNIFORMS, &count)
+	gl.GetProgramiv(id, gl.ACTIVE_UNIFORM_MAX_LENGTH, &maxLength)
+	name := make([]uint8, maxLength+1)
+
+	infos := make([]UniformInfo, count)
+	for i := range infos {
+		index := uint32(i)
+		var length, size, block, offset int32
+		var xtype uint32
+		gl.GetActiveUniform(id, index, int32(len(name)), &length, &size, &xtype, &name[0])
+		gl.GetActiveUniformsiv(id, 1, &index, gl.UNIFORM_BLOCK_INDEX, &block)
+		gl.GetActiveUniformsiv(id, 1, &index, gl.UNIFORM_OFFSET, &offset)
+
+		info := UniformInfo{
+			Name:     strings.TrimSuffix(string(name[:length]), "[0]"),
+			Type:     GlType(xtype),
+			Size:     int(size),
+			Location: -1,
+			Block:    int(block),
+			Offset:   int(offset),
+		}
+		if block == -1 {
+			info.Location = gl.GetUniformLocation(id, gl.Str(utils.NullTerm(info.Name)))
+		}
+		infos[i] = info
+	}
+	cache.uniformInfos = infos
+	return infos
+}
+
+//Returns the active uniform with that name
+func (prog *Program) UniformInfo(name string) (UniformInfo, bool) {
+	for _, info := range prog.Uniforms() {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return UniformInfo{}, false
+}
+
+//The active vertex attributes, as reported by glGetActiveAttrib
+func (prog *Program) Attributes() []AttributeInfo {
+	cache := prog.getCache()
+	if cache.attributes != nil {
+		return cache.attributes
+	}
+
+	id := prog.Id()
+	var count, maxLength int32
+	gl.GetProgramiv(id, gl.ACTIVE_ATTRIBUTES, &count)
+	gl.GetProgramiv(id, gl.ACTIVE_ATTRIBUTE_MAX_LENGTH, &maxLength)
+	name := make([]uint8, maxLength+1)
+
+	infos := make([]AttributeInfo, count)
+	for i := range infos {
+		var length, size int32
+		var xtype uint32
+		gl.GetActiveAttrib(id, uint32(i), int32(len(name)), &length, &size, &xtype, &name[0])
+		attribName := strings.TrimSuffix(string(name[:length]), "[0]")
+		infos[i] = AttributeInfo{
+			Name:     attribName,
+			Type:     GlType(xtype),
+			Size:     int(size),
+			Location: gl.GetAttribLocation(id, gl.Str(utils.NullTerm(attribName))),
+		}
+	}
+	cache.attributes = infos
+	return infos
+}
+
+//The active uniform blocks, as reported by glGetActiveUniformBlock
+func (prog *Program) UniformBlocks() []UniformBlockInfo {
+	cache := prog.getCache()
+	if cache.uniformBlocks != nil {
+		return cache.uniformBlocks
+	}
+
+	id := prog.Id()
+	var count, maxLength int32
+	gl.GetProgramiv(id, gl.ACTIVE_UNIFORM_BLOCKS, &count)
+	gl.GetProgramiv(id, gl.ACTIVE_UNIFORM_BLOCK_MAX_NAME_LENGTH, &maxLength)
+	name := make([]uint8, maxLength+1)
+	uniforms := prog.Uniforms()
+
+	infos := make([]UniformBlockInfo, count)
+	for i := range infos {
+		index := uint32(i)
+		var length, binding, dataSize int32
+		gl.GetActiveUniformBlockName(id, index, int32(len(name)), &length, &name[0])
+		gl.GetActiveUniformBlockiv(id, index, gl.UNIFORM_BLOCK_BINDING, &binding)
+		gl.GetActiveUniformBlockiv(id, index, gl.UNIFORM_BLOCK_DATA_SIZE, &dataSize)
+
+		info := UniformBlockInfo{
+			Name:     string(name[:length]),
+			Index:    index,
+			Binding:  uint32(binding),
+			DataSize: int(dataSize),
+		}
+		for _, uniform := range uniforms {
+			if uniform.Block == i {
+				info.Members = append(info.Members, uniform.Name)
+			}
+		}
+		infos[i] = info
+	}
+	cache.uniformBlocks = infos
+	return infos
+}
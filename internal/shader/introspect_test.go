@@ -0,0 +1,122 @@
+package shader_test
+
+import (
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/shader"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+const introspectVert = `#version 330 core
+layout(location = 1) in vec2 in_position;
+in vec3 in_color[2];
+out vec3 pass_color;
+
+uniform mat4 u_transform;
+
+layout(std140) uniform Lights {
+	vec4 light_color;
+	vec3 light_position;
+};
+
+void main() {
+	gl_Position = u_transform * vec4(in_position, 0., 1.);
+	pass_color = in_color[0] * in_color[1] * light_color.rgb + light_position;
+}`
+
+const introspectFrag = `#version 330 core
+in vec3 pass_color;
+out vec4 out_color;
+
+struct Material {
+	vec3 tint;
+	bool enabled;
+};
+
+uniform sampler2D u_tex;
+uniform float u_weights[4];
+uniform Material u_material;
+
+void main() {
+	vec3 tint = u_material.enabled ? u_material.tint : vec3(1.);
+	out_color = texture(u_tex, pass_color.xy) * vec4(tint, 1.) * (u_weights[0] + u_weights[3]);
+}`
+
+func newProgram(t *testing.T, vert, frag string) *shader.Program {
+	vs, err := shader.NewVertexShader(vert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vs.Destroy()
+	fs, err := shader.NewFragmentShader(frag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Destroy()
+	prog, err := shader.NewProgram(vs, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prog
+}
+
+func TestGlType(t *testing.T) {
+	assert.Equal(t, "vec3", shader.GlType(gl.FLOAT_VEC3).String())
+	assert.Equal(t, "mat4x3", shader.GlType(gl.FLOAT_MAT4x3).String())
+	assert.Equal(t, "GlType(0x0001)", shader.GlType(1).String())
+	assert.True(t, shader.GlType(gl.UNSIGNED_INT_SAMPLER_2D_ARRAY).IsSampler())
+	assert.False(t, shader.GlType(gl.INT).IsSampler())
+}
+
+func TestIntrospection(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	prog := newProgram(t, introspectVert, introspectFrag)
+	defer prog.Destroy()
+
+	uniforms := map[string]shader.UniformInfo{}
+	for _, info := range prog.Uniforms() {
+		uniforms[info.Name] = info
+	}
+	assert.Len(t, uniforms, 7)
+	assert.Equal(t, shader.GlType(gl.FLOAT_MAT4), uniforms["u_transform"].Type)
+	assert.Equal(t, -1, uniforms["u_transform"].Block)
+	assert.NotEqual(t, int32(-1), uniforms["u_transform"].Location)
+	assert.Equal(t, 4, uniforms["u_weights"].Size)
+	assert.Equal(t, shader.GlType(gl.BOOL), uniforms["u_material.enabled"].Type)
+	assert.True(t, uniforms["u_tex"].Type.IsSampler())
+	assert.Equal(t, int32(-1), uniforms["light_position"].Location)
+	assert.Equal(t, 16, uniforms["light_position"].Offset)
+
+	blocks := prog.UniformBlocks()
+	if assert.Len(t, blocks, 1) {
+		assert.Equal(t, "Lights", blocks[0].Name)
+		assert.Equal(t, 32, blocks[0].DataSize)
+		assert.ElementsMatch(t, []string{"light_color", "light_position"}, blocks[0].Members)
+	}
+
+	attributes := map[string]shader.AttributeInfo{}
+	for _, info := range prog.Attributes() {
+		attributes[info.Name] = info
+	}
+	assert.Equal(t, int32(1), attributes["in_position"].Location)
+	assert.Equal(t, 2, attributes["in_color"].Size)
+
+	info, ok := prog.UniformInfo("u_material.tint")
+	assert.True(t, ok)
+	assert.Equal(t, shader.GlType(gl.FLOAT_VEC3), info.Type)
+
+	//Copies share the cached uniforms
+	uni, err := prog.Uniform("u_transform")
+	assert.NoError(t, err)
+	copied := *prog
+	cached, _ := copied.Uniform("u_transform")
+	assert.True(t, uni == cached)
+
+	prog.Bind()
+	assert.NoError(t, prog.SetUniform("u_weights", float32(1)))
+	assert.IsType(t, shader.UniformLinkError{}, prog.SetUniform("u_missing", float32(1)))
+}
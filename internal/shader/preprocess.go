@@ -0,0 +1,184 @@
+package shader
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Qendolin/go-printpixel/internal/assets"
+)
+
+type IncludeErr struct {
+	//Where the include directive is
+	File string
+	Line int
+	Err  error
+}
+
+func (ierr IncludeErr) Error() string {
+	return fmt.Sprintf("Failed to process include in %v:%v: %v", ierr.File, ierr.Line, ierr.Err)
+}
+
+//The original location of a line of preprocessed source
+type SourceLine struct {
+	//Empty for lines that were injected, e.g. defines
+	File string
+	Line int
+}
+
+//The original location of every line of a preprocessed source, the first entry is line 1
+type LineMap []SourceLine
+
+//Returns false if the line is out of range or was injected
+func (lm LineMap) Lookup(line int) (SourceLine, bool) {
+	if line < 1 || line > len(lm) || lm[line-1].File == "" {
+		return SourceLine{}, false
+	}
+	return lm[line-1], true
+}
+
+type Preprocessed struct {
+	Source string
+	Lines  LineMap
+	//Every file that was read, starting with the main file
+	Files []string
+}
+
+/*
+	Reads a shader from src and expands its include directives.
+
+	Files are included at most once, further includes of the same file are removed.
+	`#include "file"` is resolved relative to the including file, `#include <file>` relative to the root of src.
+	Includes are expanded regardless of conditional directives.
+
+	defines - are inserted after the #version directive, the values may be empty
+*/
+func Preprocess(src assets.Source, name string, defines map[string]string) (*Preprocessed, error) {
+	pp := preprocessor{
+		src:      src,
+		included: make(map[string]bool),
+		result:   &Preprocessed{},
+	}
+
+	names := make([]string, 0, len(defines))
+	for define := range defines {
+		names = append(names, define)
+	}
+	sort.Strings(names)
+	directives := make([]string, len(names))
+	for i, define := range names {
+		directives[i] = strings.TrimSpace("#define " + define + " " + defines[define])
+	}
+
+	if err := pp.file(name, directives); err != nil {
+		return nil, err
+	}
+	pp.result.Source = pp.out.String()
+	return pp.result, nil
+}
+
+type preprocessor struct {
+	src      assets.Source
+	included map[string]bool
+	out      strings.Builder
+	result   *Preprocessed
+}
+
+func (pp *preprocessor) line(text string, origin SourceLine) {
+	pp.out.WriteString(text)
+	pp.out.WriteByte('\n')
+	pp.result.Lines = append(pp.result.Lines, origin)
+}
+
+//The defines are inserted after the #version directive, or at the start if there is none
+func (pp *preprocessor) file(name string, defines []string) error {
+	pp.included[name] = true
+	pp.result.Files = append(pp.result.Files, name)
+
+	content, err := assets.ReadFile(pp.src, name)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.Replace(string(content), "\r\n", "\n", -1), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	version := -1
+	for i, line := range lines {
+		if directive(line, "version") != "" {
+			version = i
+			break
+		}
+	}
+	if version == -1 {
+		for _, define := range defines {
+			pp.line(define, SourceLine{})
+		}
+	}
+
+	for i, line := range lines {
+		origin := SourceLine{File: name, Line: i + 1}
+		if arg := directive(line, "include"); arg != "" {
+			include, err := includePath(name, arg)
+			if err != nil {
+				return IncludeErr{File: name, Line: i + 1, Err: err}
+			}
+			if pp.included[include] {
+				continue
+			}
+			if err = pp.file(include, nil); err != nil {
+				if _, ok := err.(IncludeErr); ok {
+					return err
+				}
+				return IncludeErr{File: name, Line: i + 1, Err: err}
+			}
+			continue
+		}
+
+		pp.line(line, origin)
+		if i == version {
+			for _, define := range defines {
+				pp.line(define, SourceLine{})
+			}
+		}
+	}
+	return nil
+}
+
+//Returns the trimmed argument of the directive or "" if the line is not that directive
+func directive(line, name string) string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "#") {
+		return ""
+	}
+	line = strings.TrimSpace(line[1:])
+	if !strings.HasPrefix(line, name) {
+		return ""
+	}
+	arg := line[len(name):]
+	if arg == "" || (arg[0] != ' ' && arg[0] != '\t' && arg[0] != '"' && arg[0] != '<') {
+		return ""
+	}
+	return strings.TrimSpace(arg)
+}
+
+func includePath(from, arg string) (string, error) {
+	if len(arg) < 2 {
+		return "", fmt.Errorf("Malformed include %v", arg)
+	}
+	name := arg[1 : len(arg)-1]
+	switch {
+	case name == "":
+		return "", fmt.Errorf("Malformed include %v", arg)
+	case arg[0] == '<' && arg[len(arg)-1] == '>':
+		return path.Clean(name), nil
+	case arg[0] != '"' || arg[len(arg)-1] != '"':
+		return "", fmt.Errorf("Malformed include %v", arg)
+	case filepath.IsAbs(from):
+		return filepath.Join(filepath.Dir(from), filepath.FromSlash(name)), nil
+	}
+	return path.Join(path.Dir(from), name), nil
+}
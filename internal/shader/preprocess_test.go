@@ -0,0 +1,80 @@
+package shader_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/assets"
+	"github.com/Qendolin/go-printpixel/internal/shader"
+	"github.com/stretchr/testify/assert"
+)
+
+type files map[string]string
+
+func (f files) Open(name string) (io.ReadCloser, error) {
+	content, ok := f[name]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return ioutil.NopCloser(strings.NewReader(content)), nil
+}
+
+func TestPreprocess(t *testing.T) {
+	src := files{
+		"shaders/main.frag":      "#version 330 core\n#include \"lib/color.glsl\"\n  #include <shaders/lib/noise.glsl>\nvoid main() {}\n",
+		"shaders/lib/color.glsl": "#include \"noise.glsl\"\nvec3 gray;\n",
+		"shaders/lib/noise.glsl": "float noise;",
+	}
+	pp, err := shader.Preprocess(src, "shaders/main.frag", map[string]string{"SAMPLES": "4", "DEBUG": ""})
+	assert.NoError(t, err)
+	assert.Equal(t, "#version 330 core\n#define DEBUG\n#define SAMPLES 4\nfloat noise;\nvec3 gray;\nvoid main() {}\n", pp.Source)
+	assert.Equal(t, []string{"shaders/main.frag", "shaders/lib/color.glsl", "shaders/lib/noise.glsl"}, pp.Files)
+
+	_, ok := pp.Lines.Lookup(2)
+	assert.False(t, ok, "defines are injected")
+	line, ok := pp.Lines.Lookup(5)
+	assert.True(t, ok)
+	assert.Equal(t, shader.SourceLine{File: "shaders/lib/color.glsl", Line: 2}, line)
+	line, _ = pp.Lines.Lookup(6)
+	assert.Equal(t, shader.SourceLine{File: "shaders/main.frag", Line: 4}, line)
+	_, ok = pp.Lines.Lookup(7)
+	assert.False(t, ok)
+}
+
+func TestPreprocessWithoutVersion(t *testing.T) {
+	pp, err := shader.Preprocess(files{"a.glsl": "float a;"}, "a.glsl", map[string]string{"A": "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "#define A 1\nfloat a;\n", pp.Source)
+}
+
+func TestPreprocessErrors(t *testing.T) {
+	src := files{
+		"main.frag":  "#version 330 core\n#include \"lib.glsl\"\n",
+		"lib.glsl":   "\n#include \"missing.glsl\"\n",
+		"bad.frag":   "#include lib.glsl\n",
+		"cycle.glsl": "#include \"cycle.glsl\"\nfloat c;\n",
+	}
+	_, err := shader.Preprocess(src, "main.frag", nil)
+	ierr, ok := err.(shader.IncludeErr)
+	assert.True(t, ok, "%v", err)
+	assert.Equal(t, "lib.glsl", ierr.File)
+	assert.Equal(t, 2, ierr.Line)
+	assert.True(t, os.IsNotExist(ierr.Err))
+
+	_, err = shader.Preprocess(src, "bad.frag", nil)
+	assert.IsType(t, shader.IncludeErr{}, err)
+
+	pp, err := shader.Preprocess(src, "cycle.glsl", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "float c;\n", pp.Source)
+}
+
+func TestBuiltinIncludes(t *testing.T) {
+	pp, err := shader.Preprocess(assets.Builtin, "assets/shaders/quad_hdr.frag", nil)
+	assert.NoError(t, err)
+	assert.Contains(t, pp.Source, "vec3 aces(vec3 x)")
+	assert.Equal(t, []string{"assets/shaders/quad_hdr.frag", "assets/shaders/tonemap.glsl"}, pp.Files)
+}
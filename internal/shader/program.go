@@ -2,8 +2,8 @@ package shader
 
 import (
 	"fmt"
-	"strings"
 
+	"github.com/Qendolin/go-printpixel/internal/tracker"
 	"github.com/Qendolin/go-printpixel/internal/utils"
 	"github.com/go-gl/gl/v3.3-core/gl"
 )
@@ -11,18 +11,23 @@ import (
 type LinkErr struct {
 	Log     string
 	Program uint32
+	//Parsed from the log, linkers rarely report locations
+	Diagnostics []Diagnostic
 }
 
 func (lerr LinkErr) Error() string {
-	return fmt.Sprintf("Failed to link shaders to program (id: %v). Info: \n\n%v", lerr.Program, lerr.Log)
+	return fmt.Sprintf("Failed to link shaders to program (id: %v). Info: \n\n%v", lerr.Program, formatDiagnostics(lerr.Diagnostics, lerr.Log))
 }
 
 type Program struct {
 	*uint32
+	//Shared by copies of the program
+	cache *programCache
 }
 
 func NewProgram(vertShader *Shader, fragShader *Shader) (prog *Program, err error) {
 	id := gl.CreateProgram()
+	tracker.Track(tracker.Program, id)
 	gl.AttachShader(id, vertShader.Id())
 	gl.AttachShader(id, fragShader.Id())
 	gl.LinkProgram(id)
@@ -32,13 +37,15 @@ func NewProgram(vertShader *Shader, fragShader *Shader) (prog *Program, err erro
 	var ok int32
 	gl.GetProgramiv(id, gl.LINK_STATUS, &ok)
 	if ok == gl.FALSE {
+		log := readProgramInfoLog(id)
 		err = LinkErr{
-			Log:     readProgramInfoLog(id),
-			Program: id,
+			Log:         log,
+			Program:     id,
+			Diagnostics: ParseLog(log, "", nil),
 		}
 	}
 
-	prog = &Program{&id}
+	prog = &Program{&id, newProgramCache()}
 	return
 }
 
@@ -76,6 +83,7 @@ func (prog *Program) BindFor(context utils.BindingClosure) {
 }
 
 func (prog *Program) Destroy() {
+	tracker.Untrack(tracker.Program, prog.Id())
 	gl.DeleteProgram(prog.Id())
 	prog.uint32 = nil
 }
@@ -84,7 +92,10 @@ func readProgramInfoLog(id uint32) string {
 	var logLength int32
 	gl.GetProgramiv(id, gl.INFO_LOG_LENGTH, &logLength)
 
-	log := strings.Repeat("\x00", int(logLength+1))
-	gl.GetProgramInfoLog(id, logLength, nil, gl.Str(log))
-	return log
+	if logLength == 0 {
+		return ""
+	}
+	log := make([]uint8, logLength)
+	gl.GetProgramInfoLog(id, logLength, &logLength, &log[0])
+	return string(log[:logLength])
 }
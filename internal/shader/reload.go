@@ -0,0 +1,150 @@
+package shader
+
+import (
+	"time"
+
+	"github.com/Qendolin/go-printpixel/internal/assets"
+)
+
+/*
+	A program that is rebuilt when its source files change.
+
+	The program is swapped in place, so copies of the embedded Program and uniforms from Program.Uniform stay valid.
+	Uniform values are lost on reload and have to be set again.
+*/
+type ReloadableProgram struct {
+	*Program
+	//The minimum time between two checks of Poll
+	Interval time.Duration
+
+	src      assets.Source
+	vertPath string
+	fragPath string
+	defines  map[string]string
+	modTimes map[string]time.Time
+	lastPoll time.Time
+}
+
+/*
+	Builds the program from two shader files of src, the sources are preprocessed. See Preprocess
+
+	defines - are used for both shaders
+*/
+func NewReloadableProgram(src assets.Source, vertPath, fragPath string, defines map[string]string) (*ReloadableProgram, error) {
+	rp := &ReloadableProgram{
+		Interval: 250 * time.Millisecond,
+		src:      src,
+		vertPath: vertPath,
+		fragPath: fragPath,
+		defines:  defines,
+		modTimes: make(map[string]time.Time),
+	}
+	prog, err := rp.build()
+	if err != nil {
+		return nil, err
+	}
+	rp.Program = prog
+	return rp, nil
+}
+
+//Checks whether any of the source files, including the included ones, has been modified since the last build
+func (rp *ReloadableProgram) Changed() bool {
+	for file, modTime := range rp.modTimes {
+		if !rp.modTime(file).Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	Reloads the program if the source files changed and Interval has passed since the last check.
+	Call it once per frame.
+
+	reloaded - is true if the program was swapped
+	err - the IncludeErr, CompileErr or LinkErr of a failed reload or the error reading a file, the old program is kept
+*/
+func (rp *ReloadableProgram) Poll() (reloaded bool, err error) {
+	now := time.Now()
+	if now.Sub(rp.lastPoll) < rp.Interval {
+		return false, nil
+	}
+	rp.lastPoll = now
+
+	if !rp.Changed() {
+		return false, nil
+	}
+	if err = rp.Reload(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//Rebuilds the program and swaps it in if it compiles and links, otherwise the old program is kept
+func (rp *ReloadableProgram) Reload() error {
+	prog, err := rp.build()
+	if err != nil {
+		return err
+	}
+
+	old := rp.Id()
+	*rp.Program.uint32 = prog.Id()
+	(&Program{uint32: &old}).Destroy()
+	rp.resetCache()
+	return nil
+}
+
+//Compiles and links the shaders and records the modification times of all files that were read
+func (rp *ReloadableProgram) build() (*Program, error) {
+	vs, err := rp.compile(rp.vertPath, TypeVertex)
+	if err != nil {
+		return nil, err
+	}
+	defer vs.Destroy()
+
+	fs, err := rp.compile(rp.fragPath, TypeFragment)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.Destroy()
+
+	prog, err := NewProgram(vs, fs)
+	if err != nil {
+		prog.Destroy()
+		return nil, err
+	}
+	return prog, nil
+}
+
+func (rp *ReloadableProgram) compile(path string, shaderType ShaderType) (*Shader, error) {
+	pp, err := Preprocess(rp.src, path, rp.defines)
+	if err != nil {
+		//The includes are unknown, but the file is watched so a fix is noticed
+		rp.recordModTimes([]string{path})
+		return nil, err
+	}
+	//Right after reading, so a change while the shader compiles is noticed by the next Poll
+	rp.recordModTimes(pp.Files)
+
+	shader, err := newShader(pp.Source, shaderType, pp.Lines)
+	if err != nil {
+		shader.Destroy()
+		return nil, err
+	}
+	return shader, nil
+}
+
+func (rp *ReloadableProgram) recordModTimes(files []string) {
+	for _, file := range files {
+		rp.modTimes[file] = rp.modTime(file)
+	}
+}
+
+//Missing files have a zero time, so creating them again is noticed
+func (rp *ReloadableProgram) modTime(file string) time.Time {
+	modTime, err := assets.ModTime(rp.src, file)
+	if err != nil {
+		return time.Time{}
+	}
+	return modTime
+}
@@ -0,0 +1,87 @@
+package shader_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Qendolin/go-printpixel/internal/assets"
+	"github.com/Qendolin/go-printpixel/internal/shader"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+//Writes the file with a modification time that differs from the previous one
+func writeShader(t *testing.T, dir, name, content string, age time.Duration) {
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadableProgram(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	dir, err := ioutil.TempDir("", "printpixel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	vert, err := assets.ReadFile(assets.Builtin, "assets/shaders/quad_uniform.vert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	frag := "#version 330 core\nout vec4 out_color;\n#include \"color.glsl\"\nvoid main() { out_color = vec4(u_color, 1.); }\n"
+	writeShader(t, dir, "quad.vert", string(vert), time.Hour)
+	writeShader(t, dir, "quad.frag", frag, time.Hour)
+	writeShader(t, dir, "color.glsl", "uniform vec3 u_color;\n", time.Hour)
+
+	prog, err := shader.NewReloadableProgram(assets.Dir(dir), "quad.vert", "quad.frag", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.Destroy()
+	prog.Interval = 0
+	copied := *prog.Program
+	uColor, err := prog.Uniform("u_color")
+	assert.NoError(t, err)
+
+	reloaded, err := prog.Poll()
+	assert.False(t, reloaded)
+	assert.NoError(t, err)
+
+	//Changing an included file reloads the program, the old copy and the uniform follow
+	id := prog.Id()
+	writeShader(t, dir, "color.glsl", "uniform float u_unused;\nuniform vec3 u_color;\n", time.Minute)
+	reloaded, err = prog.Poll()
+	assert.True(t, reloaded)
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, prog.Id())
+	assert.Equal(t, prog.Id(), copied.Id())
+	expected, _ := shader.NewUniform(*prog.Program, "u_color")
+	assert.Equal(t, *expected, *uColor)
+
+	//A broken file keeps the program and reports the error once
+	id = prog.Id()
+	writeShader(t, dir, "color.glsl", "uniform vec3 u_color\n", 0)
+	reloaded, err = prog.Poll()
+	assert.False(t, reloaded)
+	if assert.IsType(t, shader.CompileErr{}, err) {
+		diags := err.(shader.CompileErr).Diagnostics
+		assert.NotEmpty(t, diags)
+		assert.True(t, strings.HasSuffix(diags[0].File, "glsl") || strings.HasSuffix(diags[0].File, "frag"), diags[0].File)
+	}
+	assert.Equal(t, id, prog.Id())
+	reloaded, err = prog.Poll()
+	assert.False(t, reloaded)
+	assert.NoError(t, err)
+}
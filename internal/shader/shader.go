@@ -2,9 +2,9 @@ package shader
 
 import (
 	"fmt"
-	"io/ioutil"
-	"strings"
 
+	"github.com/Qendolin/go-printpixel/internal/assets"
+	"github.com/Qendolin/go-printpixel/internal/tracker"
 	"github.com/Qendolin/go-printpixel/internal/utils"
 	"github.com/go-gl/gl/v3.3-core/gl"
 )
@@ -19,10 +19,12 @@ const (
 type CompileErr struct {
 	Log    string
 	Shader uint32
+	//Parsed from the log, locations point to the original files if the source was preprocessed
+	Diagnostics []Diagnostic
 }
 
 func (cerr CompileErr) Error() string {
-	return fmt.Sprintf("Failed to compile shader (id: %v). Compiler Log: \n\n%v\n\n", cerr.Shader, cerr.Log)
+	return fmt.Sprintf("Failed to compile shader (id: %v). Compiler Log: \n\n%v\n\n", cerr.Shader, formatDiagnostics(cerr.Diagnostics, cerr.Log))
 }
 
 type Shader struct {
@@ -30,8 +32,13 @@ type Shader struct {
 }
 
 func NewShader(source string, shaderType ShaderType) (*Shader, error) {
+	return newShader(source, shaderType, nil)
+}
+
+func newShader(source string, shaderType ShaderType, lines LineMap) (*Shader, error) {
 	id := gl.CreateShader(uint32(shaderType))
-	err := loadAndCompileShader(id, source)
+	tracker.Track(tracker.Shader, id)
+	err := loadAndCompileShader(id, source, lines)
 	return &Shader{&id}, err
 }
 
@@ -43,17 +50,25 @@ func NewFragmentShader(source string) (*Shader, error) {
 	return NewShader(source, TypeFragment)
 }
 
-func NewShaderFromPath(modulePath string, shaderType ShaderType) (*Shader, error) {
-	absPath, err := utils.ResolvePath(modulePath)
-	if err != nil {
-		return nil, err
-	}
-	source, err := ioutil.ReadFile(absPath)
+//Loads the shader source from assets.Default, which has the built-in shaders and falls back to files relative to the module root.
+//Absolute paths are loaded from the file system, use NewShaderFromSource with assets.Dir for other directories.
+func NewShaderFromPath(path string, shaderType ShaderType) (*Shader, error) {
+	return NewShaderFromSource(assets.Default, path, shaderType)
+}
+
+//Loads the shader source from src and expands its includes. See Preprocess
+func NewShaderFromSource(src assets.Source, path string, shaderType ShaderType) (*Shader, error) {
+	return NewShaderVariant(src, path, shaderType, nil)
+}
+
+//Like NewShaderFromSource but the defines are inserted after the #version directive
+func NewShaderVariant(src assets.Source, path string, shaderType ShaderType, defines map[string]string) (*Shader, error) {
+	pp, err := Preprocess(src, path, defines)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewShader(string(source), shaderType)
+	return newShader(pp.Source, shaderType, pp.Lines)
 }
 
 func (shader *Shader) Id() uint32 {
@@ -61,13 +76,13 @@ func (shader *Shader) Id() uint32 {
 }
 
 func (shader *Shader) Destroy() {
+	tracker.Untrack(tracker.Shader, shader.Id())
 	gl.DeleteShader(shader.Id())
 	shader.uint32 = nil
 }
 
-func loadAndCompileShader(id uint32, source string) error {
-	source = utils.NullTerm(source)
-	cStrs, free := gl.Strs(source)
+func loadAndCompileShader(id uint32, source string, lines LineMap) error {
+	cStrs, free := gl.Strs(utils.NullTerm(source))
 	gl.ShaderSource(id, 1, cStrs, nil)
 	free()
 	gl.CompileShader(id)
@@ -75,9 +90,11 @@ func loadAndCompileShader(id uint32, source string) error {
 	var ok int32
 	gl.GetShaderiv(id, gl.COMPILE_STATUS, &ok)
 	if ok == gl.FALSE {
+		log := readShaderInfoLog(id)
 		return CompileErr{
-			Log:    readShaderInfoLog(id),
-			Shader: id,
+			Log:         log,
+			Shader:      id,
+			Diagnostics: ParseLog(log, source, lines),
 		}
 	}
 	return nil
@@ -87,7 +104,10 @@ func readShaderInfoLog(id uint32) string {
 	var logLength int32
 	gl.GetShaderiv(id, gl.INFO_LOG_LENGTH, &logLength)
 
-	log := strings.Repeat("\x00", int(logLength+1))
-	gl.GetShaderInfoLog(id, logLength, nil, gl.Str(log))
-	return log
+	if logLength == 0 {
+		return ""
+	}
+	log := make([]uint8, logLength)
+	gl.GetShaderInfoLog(id, logLength, &logLength, &log[0])
+	return string(log[:logLength])
 }
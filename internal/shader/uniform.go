@@ -2,8 +2,9 @@ package shader
 
 import (
 	"fmt"
-	"log"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/Qendolin/go-printpixel/internal/utils"
 	"github.com/go-gl/gl/v3.3-core/gl"
@@ -13,6 +14,16 @@ import (
 
 type Uniform struct {
 	*int32
+	//Shared by copies, updated when a ReloadableProgram is reloaded
+	meta *uniformMeta
+}
+
+type uniformMeta struct {
+	name string
+	//0 if the uniform could not be introspected, then values are not checked
+	glType GlType
+	//The number of array elements from this uniform to the end of the array
+	size int
 }
 
 type UniformLinkError struct {
@@ -24,9 +35,29 @@ func (ulerr UniformLinkError) Error() string {
 	return fmt.Sprintf("Failed to get the location of uniform '%v'. Program: %v", ulerr.Name, ulerr.Program)
 }
 
+type UniformTypeErr struct {
+	Name  string
+	Type  GlType
+	Value string
+}
+
+func (uterr UniformTypeErr) Error() string {
+	return fmt.Sprintf("Cannot set uniform '%v' of type %v to a value of type %v", uterr.Name, uterr.Type, uterr.Value)
+}
+
+type UniformSizeErr struct {
+	Name string
+	//The remaining array elements
+	Size  int
+	Count int
+}
+
+func (userr UniformSizeErr) Error() string {
+	return fmt.Sprintf("Cannot set %v elements of uniform '%v', it has %v", userr.Count, userr.Name, userr.Size)
+}
+
 func NewUniform(prog Program, name string) (uni *Uniform, err error) {
-	name = utils.NullTerm(name)
-	loc := gl.GetUniformLocation(*prog.uint32, gl.Str(name))
+	loc := gl.GetUniformLocation(*prog.uint32, gl.Str(utils.NullTerm(name)))
 	if loc == -1 {
 		err = UniformLinkError{
 			Name:    name,
@@ -34,63 +65,233 @@ func NewUniform(prog Program, name string) (uni *Uniform, err error) {
 		}
 	}
 
-	uni = &Uniform{&loc}
+	uni = &Uniform{&loc, &uniformMeta{name: name}}
+	uni.meta.glType, uni.meta.size = prog.uniformType(name)
 	return
 }
 
-func (u *Uniform) Set(value interface{}) {
-	for refVal := reflect.ValueOf(value); refVal.Kind() == reflect.Ptr; refVal = reflect.ValueOf(value) {
-		value = refVal.Elem().Interface()
-	}
-
-	switch v := value.(type) {
-	case float64:
-		gl.Uniform1d(*u.int32, v)
-	case float32:
-		gl.Uniform1f(*u.int32, v)
-	case int:
-		gl.Uniform1i(*u.int32, int32(v))
-	case int64:
-		gl.Uniform1i(*u.int32, int32(v))
-	case int32:
-		gl.Uniform1i(*u.int32, int32(v))
-	case int16:
-		gl.Uniform1i(*u.int32, int32(v))
-	case int8:
-		gl.Uniform1i(*u.int32, int32(v))
-	case uint:
-		gl.Uniform1ui(*u.int32, uint32(v))
-	case uint64:
-		gl.Uniform1ui(*u.int32, uint32(v))
-	case uint32:
-		gl.Uniform1ui(*u.int32, uint32(v))
-	case uint16:
-		gl.Uniform1ui(*u.int32, uint32(v))
-	case uint8:
-		gl.Uniform1ui(*u.int32, uint32(v))
-	case mgl32.Vec2:
-		gl.Uniform2f(*u.int32, v.X(), v.Y())
-	case mgl64.Vec2:
-		gl.Uniform2d(*u.int32, v.X(), v.Y())
-	case mgl32.Vec3:
-		gl.Uniform3f(*u.int32, v.X(), v.Y(), v.Z())
-	case mgl64.Vec3:
-		gl.Uniform3d(*u.int32, v.X(), v.Y(), v.Z())
-	case mgl32.Vec4:
-		gl.Uniform4f(*u.int32, v.X(), v.Y(), v.Z(), v.W())
-	case mgl64.Vec4:
-		gl.Uniform4d(*u.int32, v.X(), v.Y(), v.Z(), v.W())
-	case mgl32.Mat3:
-		gl.UniformMatrix3fv(*u.int32, 1, false, &v[0])
-	case mgl64.Mat3:
-		gl.UniformMatrix3dv(*u.int32, 1, false, &v[0])
-	case mgl32.Mat4:
-		gl.UniformMatrix4fv(*u.int32, 1, false, &v[0])
-	case mgl64.Mat4:
-		gl.UniformMatrix4dv(*u.int32, 1, false, &v[0])
-	default:
-		reflectType := reflect.TypeOf(value)
-		dataType := reflectType.String()
-		log.Printf("Unsupported type %v", dataType)
+//-1 if the uniform is not active
+func (u *Uniform) Location() int32 {
+	return *u.int32
+}
+
+//Looks up the type of a uniform, the name may be an element of an array, e.g. "u_colors[2]"
+func (prog *Program) uniformType(name string) (GlType, int) {
+	if info, ok := prog.UniformInfo(name); ok {
+		return info.Type, info.Size
+	}
+	open := strings.LastIndexByte(name, '[')
+	if open == -1 || !strings.HasSuffix(name, "]") {
+		return 0, 0
+	}
+	index, err := strconv.Atoi(name[open+1 : len(name)-1])
+	if err != nil {
+		return 0, 0
+	}
+	if info, ok := prog.UniformInfo(name[:open]); ok && index < info.Size {
+		return info.Type, info.Size - index
+	}
+	return 0, 0
+}
+
+//The component type and dimensions of a Go value
+type valueShape struct {
+	component uint32
+	columns   int
+	rows      int
+}
+
+var matrixShapes = map[reflect.Type]valueShape{
+	reflect.TypeOf(mgl32.Mat2{}):   {gl.FLOAT, 2, 2},
+	reflect.TypeOf(mgl32.Mat2x3{}): {gl.FLOAT, 2, 3},
+	reflect.TypeOf(mgl32.Mat2x4{}): {gl.FLOAT, 2, 4},
+	reflect.TypeOf(mgl32.Mat3x2{}): {gl.FLOAT, 3, 2},
+	reflect.TypeOf(mgl32.Mat3{}):   {gl.FLOAT, 3, 3},
+	reflect.TypeOf(mgl32.Mat3x4{}): {gl.FLOAT, 3, 4},
+	reflect.TypeOf(mgl32.Mat4x2{}): {gl.FLOAT, 4, 2},
+	reflect.TypeOf(mgl32.Mat4x3{}): {gl.FLOAT, 4, 3},
+	reflect.TypeOf(mgl32.Mat4{}):   {gl.FLOAT, 4, 4},
+	reflect.TypeOf(mgl64.Mat2{}):   {gl.DOUBLE, 2, 2},
+	reflect.TypeOf(mgl64.Mat2x3{}): {gl.DOUBLE, 2, 3},
+	reflect.TypeOf(mgl64.Mat2x4{}): {gl.DOUBLE, 2, 4},
+	reflect.TypeOf(mgl64.Mat3x2{}): {gl.DOUBLE, 3, 2},
+	reflect.TypeOf(mgl64.Mat3{}):   {gl.DOUBLE, 3, 3},
+	reflect.TypeOf(mgl64.Mat3x4{}): {gl.DOUBLE, 3, 4},
+	reflect.TypeOf(mgl64.Mat4x2{}): {gl.DOUBLE, 4, 2},
+	reflect.TypeOf(mgl64.Mat4x3{}): {gl.DOUBLE, 4, 3},
+	reflect.TypeOf(mgl64.Mat4{}):   {gl.DOUBLE, 4, 4},
+}
+
+func scalarComponent(kind reflect.Kind) (uint32, bool) {
+	switch kind {
+	case reflect.Float32:
+		return gl.FLOAT, true
+	case reflect.Float64:
+		return gl.DOUBLE, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return gl.INT, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return gl.UNSIGNED_INT, true
+	case reflect.Bool:
+		return gl.BOOL, true
+	}
+	return 0, false
+}
+
+//Scalars, matrices of mgl32 and mgl64 and arrays of 2 to 4 scalars as vectors, e.g. mgl32.Vec3 or [2]int32
+func shapeOf(t reflect.Type) (valueShape, bool) {
+	if shape, ok := matrixShapes[t]; ok {
+		return shape, true
+	}
+	if component, ok := scalarComponent(t.Kind()); ok {
+		return valueShape{component, 1, 1}, true
+	}
+	if t.Kind() == reflect.Array && t.Len() >= 2 && t.Len() <= 4 {
+		if component, ok := scalarComponent(t.Elem().Kind()); ok {
+			return valueShape{component, 1, t.Len()}, true
+		}
+	}
+	return valueShape{}, false
+}
+
+//The flattened components, only the slice of the component type is used. Booleans are stored as ints.
+type uniformData struct {
+	floats  []float32
+	doubles []float64
+	ints    []int32
+	uints   []uint32
+}
+
+func (data *uniformData) append(component uint32, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			data.append(component, v.Index(i))
+		}
+		return
+	}
+	switch component {
+	case gl.FLOAT:
+		data.floats = append(data.floats, float32(v.Float()))
+	case gl.DOUBLE:
+		data.doubles = append(data.doubles, v.Float())
+	case gl.INT:
+		data.ints = append(data.ints, int32(v.Int()))
+	case gl.UNSIGNED_INT:
+		data.uints = append(data.uints, uint32(v.Uint()))
+	case gl.BOOL:
+		var b int32
+		if v.Bool() {
+			b = 1
+		}
+		data.ints = append(data.ints, b)
+	}
+}
+
+/*
+	Sets the value of the uniform, the program has to be bound.
+
+	value - a scalar, vector (mgl32.Vec3, [4]int32, [2]bool ...), matrix (mgl32.Mat2x3 ...) or a slice of them for arrays.
+	Pointers are dereferenced. The value has to match the type of the uniform, samplers are set with integers.
+*/
+func (u *Uniform) Set(value interface{}) error {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return u.typeErr("nil")
+	}
+
+	count := 1
+	elemType := v.Type()
+	if v.Kind() == reflect.Slice {
+		count = v.Len()
+		elemType = elemType.Elem()
+	}
+	shape, ok := shapeOf(elemType)
+	if !ok {
+		return u.typeErr(v.Type().String())
+	}
+	if err := u.check(shape, count, v.Type()); err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	var data uniformData
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < count; i++ {
+			data.append(shape.component, v.Index(i))
+		}
+	} else {
+		data.append(shape.component, v)
+	}
+	u.upload(shape, int32(count), &data)
+	return nil
+}
+
+//Checks the shape and count against the introspected type
+func (u *Uniform) check(shape valueShape, count int, valueType reflect.Type) error {
+	if u.meta == nil || u.meta.glType == 0 {
+		return nil
+	}
+	info := glTypes[u.meta.glType]
+	expected := valueShape{info.component, info.columns, info.rows}
+	if shape != expected {
+		return u.typeErr(valueType.String())
+	}
+	if count > u.meta.size {
+		return UniformSizeErr{Name: u.meta.name, Size: u.meta.size, Count: count}
+	}
+	return nil
+}
+
+func (u *Uniform) typeErr(value string) error {
+	if u.meta == nil {
+		return UniformTypeErr{Value: value}
+	}
+	return UniformTypeErr{Name: u.meta.name, Type: u.meta.glType, Value: value}
+}
+
+var (
+	floatVectorFuncs  = [...]func(int32, int32, *float32){gl.Uniform1fv, gl.Uniform2fv, gl.Uniform3fv, gl.Uniform4fv}
+	doubleVectorFuncs = [...]func(int32, int32, *float64){gl.Uniform1dv, gl.Uniform2dv, gl.Uniform3dv, gl.Uniform4dv}
+	intVectorFuncs    = [...]func(int32, int32, *int32){gl.Uniform1iv, gl.Uniform2iv, gl.Uniform3iv, gl.Uniform4iv}
+	uintVectorFuncs   = [...]func(int32, int32, *uint32){gl.Uniform1uiv, gl.Uniform2uiv, gl.Uniform3uiv, gl.Uniform4uiv}
+	//Indexed by columns-2 and rows-2
+	floatMatrixFuncs = [3][3]func(int32, int32, bool, *float32){
+		{gl.UniformMatrix2fv, gl.UniformMatrix2x3fv, gl.UniformMatrix2x4fv},
+		{gl.UniformMatrix3x2fv, gl.UniformMatrix3fv, gl.UniformMatrix3x4fv},
+		{gl.UniformMatrix4x2fv, gl.UniformMatrix4x3fv, gl.UniformMatrix4fv},
+	}
+	doubleMatrixFuncs = [3][3]func(int32, int32, bool, *float64){
+		{gl.UniformMatrix2dv, gl.UniformMatrix2x3dv, gl.UniformMatrix2x4dv},
+		{gl.UniformMatrix3x2dv, gl.UniformMatrix3dv, gl.UniformMatrix3x4dv},
+		{gl.UniformMatrix4x2dv, gl.UniformMatrix4x3dv, gl.UniformMatrix4dv},
+	}
+)
+
+func (u *Uniform) upload(shape valueShape, count int32, data *uniformData) {
+	loc := *u.int32
+	if shape.columns > 1 {
+		switch shape.component {
+		case gl.FLOAT:
+			floatMatrixFuncs[shape.columns-2][shape.rows-2](loc, count, false, &data.floats[0])
+		case gl.DOUBLE:
+			doubleMatrixFuncs[shape.columns-2][shape.rows-2](loc, count, false, &data.doubles[0])
+		}
+		return
+	}
+	switch shape.component {
+	case gl.FLOAT:
+		floatVectorFuncs[shape.rows-1](loc, count, &data.floats[0])
+	case gl.DOUBLE:
+		doubleVectorFuncs[shape.rows-1](loc, count, &data.doubles[0])
+	case gl.INT, gl.BOOL:
+		intVectorFuncs[shape.rows-1](loc, count, &data.ints[0])
+	case gl.UNSIGNED_INT:
+		uintVectorFuncs[shape.rows-1](loc, count, &data.uints[0])
 	}
 }
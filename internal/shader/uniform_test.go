@@ -6,8 +6,10 @@ import (
 	"github.com/Qendolin/go-printpixel/internal/canvas"
 	"github.com/Qendolin/go-printpixel/internal/shader"
 	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMain(m *testing.M) {
@@ -39,3 +41,99 @@ func TestUniformColor(t *testing.T) {
 		glfw.PollEvents()
 	}
 }
+
+func TestUniformSet(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	prog := newProgram(t, introspectVert, introspectFrag)
+	defer prog.Destroy()
+	prog.Bind()
+
+	uWeights, err := prog.Uniform("u_weights")
+	assert.NoError(t, err)
+	assert.NoError(t, uWeights.Set([]float32{1, 2, 3, 4}))
+	assert.NoError(t, uWeights.Set(float32(1)))
+	assert.Equal(t, shader.UniformSizeErr{Name: "u_weights", Size: 4, Count: 5}, uWeights.Set(make([]float32, 5)))
+	assert.Equal(t, shader.UniformTypeErr{Name: "u_weights", Type: gl.FLOAT, Value: "float64"}, uWeights.Set(1.))
+
+	uLast, err := prog.Uniform("u_weights[3]")
+	assert.NoError(t, err)
+	assert.NoError(t, uLast.Set([]float32{4}))
+	assert.IsType(t, shader.UniformSizeErr{}, uLast.Set([]float32{4, 5}))
+
+	assert.NoError(t, prog.SetUniform("u_transform", mgl32.Ident4()))
+	assert.IsType(t, shader.UniformTypeErr{}, prog.SetUniform("u_transform", mgl32.Ident3()))
+	assert.NoError(t, prog.SetUniform("u_material.enabled", true))
+	assert.IsType(t, shader.UniformTypeErr{}, prog.SetUniform("u_material.enabled", 1))
+	assert.NoError(t, prog.SetUniform("u_material.tint", &mgl32.Vec3{1, 0, 1}))
+	assert.IsType(t, shader.UniformTypeErr{}, prog.SetUniform("u_material.tint", [3]int32{1, 0, 1}))
+	assert.NoError(t, prog.SetUniform("u_tex", 0))
+	assert.IsType(t, shader.UniformTypeErr{}, prog.SetUniform("u_tex", "0"))
+
+	var values [4]float32
+	gl.GetUniformfv(prog.Id(), uLast.Location(), &values[0])
+	assert.Equal(t, float32(4), values[0])
+	assert.Equal(t, uint32(gl.NO_ERROR), gl.GetError())
+}
+
+const shapesFrag = `#version 330 core
+out vec4 out_color;
+
+uniform ivec2 u_ivec;
+uniform uvec3 u_uvec;
+uniform bvec4 u_bvec;
+uniform mat2 u_mat2;
+uniform mat2x3 u_mat2x3;
+uniform mat4x3 u_mat4x3;
+uniform vec4 u_colors[3];
+uniform mat4 u_bones[2];
+
+void main() {
+	float sum = float(u_ivec.x) + float(u_uvec.y) + (u_bvec.z ? 1. : 0.) + u_mat2[0][0] + u_mat2x3[1][2] + u_mat4x3[3][1];
+	out_color = u_colors[0] + u_colors[2] + u_bones[0][0] + u_bones[1][3] + vec4(sum);
+}`
+
+func TestUniformShapes(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	prog := newProgram(t, introspectVert, shapesFrag)
+	defer prog.Destroy()
+	prog.Bind()
+
+	valid := map[string]interface{}{
+		"u_ivec":   [2]int32{1, -2},
+		"u_uvec":   [3]uint32{1, 2, 3},
+		"u_bvec":   [4]bool{true, false, true, false},
+		"u_mat2":   mgl32.Ident2(),
+		"u_mat2x3": mgl32.Mat2x3{1, 2, 3, 4, 5, 6},
+		"u_mat4x3": mgl32.Mat4x3{},
+		"u_colors": []mgl32.Vec4{{1, 0, 0, 1}, {0, 1, 0, 1}, {0, 0, 1, 1}},
+		"u_bones":  []mgl32.Mat4{mgl32.Ident4(), mgl32.Ident4()},
+	}
+	for name, value := range valid {
+		assert.NoError(t, prog.SetUniform(name, value), name)
+	}
+	assert.Equal(t, uint32(gl.NO_ERROR), gl.GetError())
+
+	invalid := map[string]interface{}{
+		"u_ivec":   [2]uint32{1, 2},
+		"u_uvec":   mgl32.Vec3{1, 2, 3},
+		"u_bvec":   [4]int32{1, 0, 1, 0},
+		"u_mat2":   mgl32.Vec4{1, 0, 0, 1},
+		"u_mat2x3": mgl32.Mat3x2{},
+		"u_mat4x3": mgl32.Mat3x4{},
+		"u_colors": []mgl32.Vec3{{1, 0, 0}},
+		"u_bones":  mgl32.Ident3(),
+	}
+	for name, value := range invalid {
+		assert.IsType(t, shader.UniformTypeErr{}, prog.SetUniform(name, value), name)
+	}
+	assert.IsType(t, shader.UniformSizeErr{}, prog.SetUniform("u_colors", make([]mgl32.Vec4, 4)))
+
+	uIvec, _ := prog.Uniform("u_ivec")
+	var ivec [2]int32
+	gl.GetUniformiv(prog.Id(), uIvec.Location(), &ivec[0])
+	assert.Equal(t, [2]int32{1, -2}, ivec)
+}
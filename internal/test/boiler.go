@@ -25,7 +25,7 @@ func (win TestingWindow) ShouldClose() bool {
 	return win.Window.ShouldClose()
 }
 
-func NewWindow(t *testing.T) (w TestingWindow, close func()) {
+func NewWindow(t testing.TB) (w TestingWindow, close func()) {
 	runtime.LockOSThread()
 	err := context.InitGlfw()
 	if err != nil {
@@ -64,7 +64,7 @@ func NewWindow(t *testing.T) (w TestingWindow, close func()) {
 	}
 }
 
-func NewProgram(t *testing.T, vsPath, fsPath string) shader.Program {
+func NewProgram(t testing.TB, vsPath, fsPath string) shader.Program {
 	vs, err := shader.NewShaderFromPath(vsPath, shader.TypeVertex)
 	if err != nil {
 		t.Fatal(err)
@@ -0,0 +1,104 @@
+package texfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	ddsMagic       = "DDS "
+	ddsHeaderSize  = 128
+	ddsDx10Size    = 20
+	ddsdMipMaps    = 0x20000
+	ddpfFourCC     = 0x4
+	ddsCaps2Cube   = 0x200
+	ddsCaps2Faces  = 0xFC00
+	ddsCaps2Volume = 0x200000
+	dx10MiscCube   = 0x4
+	dx10Texture3D  = 4
+)
+
+/*
+	Reads a DDS file with a four character code or a DX10 header.
+	The file stores each layer and face with its mipmap chain.
+*/
+func DecodeDds(r io.Reader) (*Container, error) {
+	file, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(file) < ddsHeaderSize || string(file[:4]) != ddsMagic {
+		return nil, ErrUnknownContainer
+	}
+	u32 := func(offset int) int {
+		return int(binary.LittleEndian.Uint32(file[offset:]))
+	}
+
+	flags, height, width := u32(8), u32(12), u32(16)
+	depth, levels := u32(24), 1
+	if flags&ddsdMipMaps != 0 && u32(28) > 0 {
+		levels = u32(28)
+	}
+	caps2 := u32(112)
+	if caps2&ddsCaps2Volume == 0 {
+		depth = 1
+	}
+	faces, layers := 1, 0
+	if caps2&ddsCaps2Cube != 0 {
+		if caps2&ddsCaps2Faces != ddsCaps2Faces {
+			return nil, FormatErr{Format: "partial cube map"}
+		}
+		faces = 6
+	}
+
+	if u32(80)&ddpfFourCC == 0 {
+		return nil, FormatErr{Format: "uncompressed DDS"}
+	}
+	fourCC := string(file[84:88])
+	offset := ddsHeaderSize
+	var internalFormat uint32
+	if fourCC == "DX10" {
+		if len(file) < ddsHeaderSize+ddsDx10Size {
+			return nil, ErrTruncated
+		}
+		dxgi := uint32(u32(128))
+		var ok bool
+		if internalFormat, ok = dxgiFormats[dxgi]; !ok {
+			return nil, FormatErr{Format: fmt.Sprintf("DXGI %v", dxgi)}
+		}
+		if u32(132) != dx10Texture3D {
+			depth = 1
+		}
+		if u32(136)&dx10MiscCube != 0 {
+			faces = 6
+		}
+		if arraySize := u32(140); arraySize > 1 {
+			layers = arraySize
+		}
+		offset += ddsDx10Size
+	} else {
+		var ok bool
+		if internalFormat, ok = fourCCFormats[fourCC]; !ok {
+			return nil, FormatErr{Format: fourCC}
+		}
+	}
+
+	c, info, err := newContainer(internalFormat, width, height, depth, layers, faces, levels, len(file)-offset)
+	if err != nil {
+		return nil, err
+	}
+	for image := 0; image < faces*max(layers, 1); image++ {
+		for i := range c.Levels {
+			level := &c.Levels[i]
+			size := info.imageSize(level.Width, level.Height, level.Depth)
+			if offset+size > len(file) {
+				return nil, ErrTruncated
+			}
+			level.Images[image] = file[offset : offset+size]
+			offset += size
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,97 @@
+package texfile
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+//Compressed formats that are missing from the gl package
+const (
+	compressedRgb8Etc1          = 0x8D64
+	compressedSrgbS3tcDxt1      = 0x8C4C
+	compressedSrgbAlphaS3tcDxt1 = 0x8C4D
+	compressedSrgbAlphaS3tcDxt3 = 0x8C4E
+	compressedSrgbAlphaS3tcDxt5 = 0x8C4F
+	extS3tc                     = "GL_EXT_texture_compression_s3tc"
+	extS3tcSrgb                 = "GL_EXT_texture_sRGB"
+	extBptc                     = "GL_ARB_texture_compression_bptc"
+	extEtc2                     = "GL_ARB_ES3_compatibility"
+)
+
+//A block compressed format
+type formatInfo struct {
+	//The size of a 4x4 block in bytes
+	blockSize int
+	//The extensions that have to be supported, none for formats that are core in OpenGL 3.3
+	extensions []string
+}
+
+var formats = map[uint32]formatInfo{
+	gl.COMPRESSED_RGB_S3TC_DXT1_EXT:              {8, []string{extS3tc}},
+	gl.COMPRESSED_RGBA_S3TC_DXT1_EXT:             {8, []string{extS3tc}},
+	gl.COMPRESSED_RGBA_S3TC_DXT3_EXT:             {16, []string{extS3tc}},
+	gl.COMPRESSED_RGBA_S3TC_DXT5_EXT:             {16, []string{extS3tc}},
+	compressedSrgbS3tcDxt1:                       {8, []string{extS3tc, extS3tcSrgb}},
+	compressedSrgbAlphaS3tcDxt1:                  {8, []string{extS3tc, extS3tcSrgb}},
+	compressedSrgbAlphaS3tcDxt3:                  {16, []string{extS3tc, extS3tcSrgb}},
+	compressedSrgbAlphaS3tcDxt5:                  {16, []string{extS3tc, extS3tcSrgb}},
+	gl.COMPRESSED_RED_RGTC1:                      {8, nil},
+	gl.COMPRESSED_SIGNED_RED_RGTC1:               {8, nil},
+	gl.COMPRESSED_RG_RGTC2:                       {16, nil},
+	gl.COMPRESSED_SIGNED_RG_RGTC2:                {16, nil},
+	gl.COMPRESSED_RGBA_BPTC_UNORM_ARB:            {16, []string{extBptc}},
+	gl.COMPRESSED_SRGB_ALPHA_BPTC_UNORM_ARB:      {16, []string{extBptc}},
+	gl.COMPRESSED_RGB_BPTC_SIGNED_FLOAT_ARB:      {16, []string{extBptc}},
+	gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB:    {16, []string{extBptc}},
+	gl.COMPRESSED_RGB8_ETC2:                      {8, []string{extEtc2}},
+	gl.COMPRESSED_SRGB8_ETC2:                     {8, []string{extEtc2}},
+	gl.COMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2:  {8, []string{extEtc2}},
+	gl.COMPRESSED_SRGB8_PUNCHTHROUGH_ALPHA1_ETC2: {8, []string{extEtc2}},
+	gl.COMPRESSED_RGBA8_ETC2_EAC:                 {16, []string{extEtc2}},
+	gl.COMPRESSED_SRGB8_ALPHA8_ETC2_EAC:          {16, []string{extEtc2}},
+	gl.COMPRESSED_R11_EAC:                        {8, []string{extEtc2}},
+	gl.COMPRESSED_SIGNED_R11_EAC:                 {8, []string{extEtc2}},
+	gl.COMPRESSED_RG11_EAC:                       {16, []string{extEtc2}},
+	gl.COMPRESSED_SIGNED_RG11_EAC:                {16, []string{extEtc2}},
+}
+
+//Formats that are uploaded as another format which can decode them.
+//ETC1 is a subset of ETC2, its extension only exists in OpenGL ES.
+var formatAliases = map[uint32]uint32{
+	compressedRgb8Etc1: gl.COMPRESSED_RGB8_ETC2,
+}
+
+//The size of an image in bytes, each dimension is rounded up to whole blocks
+func (info formatInfo) imageSize(width, height, depth int) int {
+	return (width + 3) / 4 * ((height + 3) / 4) * info.blockSize * depth
+}
+
+//DDS four character codes
+var fourCCFormats = map[string]uint32{
+	"DXT1": gl.COMPRESSED_RGBA_S3TC_DXT1_EXT,
+	"DXT3": gl.COMPRESSED_RGBA_S3TC_DXT3_EXT,
+	"DXT5": gl.COMPRESSED_RGBA_S3TC_DXT5_EXT,
+	"ATI1": gl.COMPRESSED_RED_RGTC1,
+	"BC4U": gl.COMPRESSED_RED_RGTC1,
+	"BC4S": gl.COMPRESSED_SIGNED_RED_RGTC1,
+	"ATI2": gl.COMPRESSED_RG_RGTC2,
+	"BC5U": gl.COMPRESSED_RG_RGTC2,
+	"BC5S": gl.COMPRESSED_SIGNED_RG_RGTC2,
+}
+
+//DXGI formats of the DDS DX10 header
+var dxgiFormats = map[uint32]uint32{
+	71: gl.COMPRESSED_RGBA_S3TC_DXT1_EXT,
+	72: compressedSrgbAlphaS3tcDxt1,
+	74: gl.COMPRESSED_RGBA_S3TC_DXT3_EXT,
+	75: compressedSrgbAlphaS3tcDxt3,
+	77: gl.COMPRESSED_RGBA_S3TC_DXT5_EXT,
+	78: compressedSrgbAlphaS3tcDxt5,
+	80: gl.COMPRESSED_RED_RGTC1,
+	81: gl.COMPRESSED_SIGNED_RED_RGTC1,
+	83: gl.COMPRESSED_RG_RGTC2,
+	84: gl.COMPRESSED_SIGNED_RG_RGTC2,
+	95: gl.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT_ARB,
+	96: gl.COMPRESSED_RGB_BPTC_SIGNED_FLOAT_ARB,
+	98: gl.COMPRESSED_RGBA_BPTC_UNORM_ARB,
+	99: gl.COMPRESSED_SRGB_ALPHA_BPTC_UNORM_ARB,
+}
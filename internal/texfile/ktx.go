@@ -0,0 +1,85 @@
+package texfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+var ktxIdentifier = []byte{0xAB, 'K', 'T', 'X', ' ', '1', '1', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+const (
+	ktxHeaderSize = 64
+	ktxEndianness = 0x04030201
+)
+
+/*
+	Reads a KTX version 1 file. Files without levels, which request generated mipmaps, are read as one level.
+	The file stores each level with all its layers and faces, padded to 4 bytes.
+*/
+func DecodeKtx(r io.Reader) (*Container, error) {
+	file, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(file) < ktxHeaderSize || string(file[:12]) != string(ktxIdentifier) {
+		return nil, ErrUnknownContainer
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(file[12:]) != ktxEndianness {
+		order = binary.BigEndian
+	}
+	u32 := func(offset int) int {
+		return int(order.Uint32(file[offset:]))
+	}
+
+	if glType := u32(16); glType != 0 {
+		return nil, FormatErr{Format: fmt.Sprintf("uncompressed KTX with type 0x%04X", glType)}
+	}
+	internalFormat := uint32(u32(28))
+	width, height, depth := u32(36), max(u32(40), 1), max(u32(44), 1)
+	layers, faces, levels := u32(48), max(u32(52), 1), max(u32(56), 1)
+
+	offset := ktxHeaderSize + u32(60)
+	if offset > len(file) {
+		return nil, ErrTruncated
+	}
+	c, info, err := newContainer(internalFormat, width, height, depth, layers, faces, levels, len(file)-offset)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range c.Levels {
+		level := &c.Levels[i]
+		if offset+4 > len(file) {
+			return nil, ErrTruncated
+		}
+		//The size of one face of non-array cube maps, otherwise of the whole level
+		imageSize := u32(offset)
+		offset += 4
+
+		size := info.imageSize(level.Width, level.Height, level.Depth)
+		expected := size * len(level.Images)
+		if faces == 6 && layers == 0 {
+			expected = size
+		}
+		if imageSize != expected {
+			return nil, FormatErr{Format: fmt.Sprintf("level %v with %v bytes, expected %v", i, imageSize, expected)}
+		}
+
+		for image := range level.Images {
+			if offset+size > len(file) {
+				return nil, ErrTruncated
+			}
+			level.Images[image] = file[offset : offset+size]
+			offset += pad4(size)
+		}
+	}
+	return c, nil
+}
+
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
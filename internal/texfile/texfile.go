@@ -0,0 +1,210 @@
+//Package texfile reads block compressed textures from DDS and KTX (version 1) containers.
+package texfile
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/Qendolin/go-printpixel/internal/context"
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+var (
+	ErrUnknownContainer = errors.New("Not a DDS or KTX file")
+	ErrTruncated        = errors.New("The texture data is truncated")
+	ErrCubeMapArray     = errors.New("Cube map arrays are not supported by OpenGL 3.3")
+)
+
+//Well above the limits of OpenGL implementations, so the sizes computed from them can't overflow
+const (
+	maxDimension = 1 << 16
+	maxLayers    = 1 << 16
+)
+
+type FormatErr struct {
+	Format string
+}
+
+func (ferr FormatErr) Error() string {
+	return fmt.Sprintf("Unsupported texture format %v, only block compressed formats are supported", ferr.Format)
+}
+
+//A header field whose value is invalid or larger than any texture OpenGL supports
+type HeaderErr struct {
+	Field string
+	Value int
+}
+
+func (herr HeaderErr) Error() string {
+	return fmt.Sprintf("Invalid texture header, the %v %v is out of range", herr.Field, herr.Value)
+}
+
+type ExtensionErr struct {
+	Extension string
+	Format    uint32
+}
+
+func (eerr ExtensionErr) Error() string {
+	return fmt.Sprintf("The texture format 0x%04X requires the unsupported extension %v", eerr.Format, eerr.Extension)
+}
+
+//A mipmap level
+type Level struct {
+	Width, Height, Depth int
+	//One image per array layer and cube face, ordered by layer and then face. A volume texture has one image with all slices.
+	Images [][]byte
+}
+
+type Container struct {
+	//A compressed format, e.g. gl.COMPRESSED_RGBA_S3TC_DXT5_EXT. ETC1 is read as gl.COMPRESSED_RGB8_ETC2
+	InternalFormat uint32
+	//The extensions the context has to support for the format
+	Extensions           []string
+	Width, Height, Depth int
+	//The number of array layers, 0 if the texture is not an array
+	Layers int
+	//6 for cube maps, otherwise 1
+	Faces  int
+	Levels []Level
+}
+
+//Reads a DDS or KTX container, depending on the file signature
+func Decode(r io.Reader) (*Container, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(ktxIdentifier))
+	if err != nil && len(magic) < 4 {
+		return nil, ErrUnknownContainer
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte(ddsMagic)):
+		return DecodeDds(br)
+	case bytes.Equal(magic, ktxIdentifier):
+		return DecodeKtx(br)
+	}
+	return nil, ErrUnknownContainer
+}
+
+/*
+	Validates the header fields and creates the container and its levels with the sizes of a mipmap chain.
+
+	dataSize - the number of bytes after the headers, the images of all levels have to fit into it
+*/
+func newContainer(internalFormat uint32, width, height, depth, layers, faces, levels, dataSize int) (*Container, formatInfo, error) {
+	if alias, ok := formatAliases[internalFormat]; ok {
+		internalFormat = alias
+	}
+	info, ok := formats[internalFormat]
+	if !ok {
+		return nil, info, FormatErr{Format: fmt.Sprintf("0x%04X", internalFormat)}
+	}
+	if faces == 6 && layers > 0 {
+		return nil, info, ErrCubeMapArray
+	}
+	if err := checkHeader(width, height, depth, layers, faces, levels); err != nil {
+		return nil, info, err
+	}
+
+	images := faces * max(layers, 1)
+	var size int64
+	for i := 0; i < levels; i++ {
+		w, h, d := max(width>>uint(i), 1), max(height>>uint(i), 1), max(depth>>uint(i), 1)
+		size += int64((w+3)/4) * int64((h+3)/4) * int64(info.blockSize) * int64(d) * int64(images)
+	}
+	if size > int64(dataSize) {
+		return nil, info, ErrTruncated
+	}
+
+	c := &Container{
+		InternalFormat: internalFormat,
+		Extensions:     info.extensions,
+		Width:          width,
+		Height:         height,
+		Depth:          depth,
+		Layers:         layers,
+		Faces:          faces,
+		Levels:         make([]Level, levels),
+	}
+	for i := range c.Levels {
+		c.Levels[i] = Level{
+			Width:  max(width>>uint(i), 1),
+			Height: max(height>>uint(i), 1),
+			Depth:  max(depth>>uint(i), 1),
+			Images: make([][]byte, images),
+		}
+	}
+	return c, info, nil
+}
+
+func checkHeader(width, height, depth, layers, faces, levels int) error {
+	switch {
+	case width < 1 || width > maxDimension:
+		return HeaderErr{Field: "width", Value: width}
+	case height < 1 || height > maxDimension:
+		return HeaderErr{Field: "height", Value: height}
+	case depth < 1 || depth > maxDimension:
+		return HeaderErr{Field: "depth", Value: depth}
+	case layers < 0 || layers > maxLayers:
+		return HeaderErr{Field: "layer count", Value: layers}
+	case faces != 1 && faces != 6:
+		return HeaderErr{Field: "face count", Value: faces}
+	}
+	//A full mipmap chain ends with a 1x1x1 level
+	if maxLevels := bits.Len(uint(max(width, max(height, depth)))); levels < 1 || levels > maxLevels {
+		return HeaderErr{Field: "level count", Value: levels}
+	}
+	return nil
+}
+
+//The texture target the container has to be uploaded to
+func (c *Container) Target() data.TexTarget {
+	switch {
+	case c.Faces == 6:
+		return data.TextureCubeMap
+	case c.Layers > 0:
+		return data.Texture2DArray
+	case c.Depth > 1:
+		return data.Texture3D
+	}
+	return data.Texture2D
+}
+
+//Uploads all levels, layers and faces. The texture has to be bound and its target has to be Target().
+func (c *Container) Upload(tex *data.Texture) error {
+	for _, ext := range c.Extensions {
+		if !context.ExtensionSupported(ext) {
+			return ExtensionErr{Extension: ext, Format: c.InternalFormat}
+		}
+	}
+	if tex.Target != c.Target() {
+		return data.TargetErr{Target: tex.Target}
+	}
+
+	for i, level := range c.Levels {
+		w, h := int32(level.Width), int32(level.Height)
+		switch {
+		case c.Faces == 6:
+			for face, img := range level.Images {
+				tex.As(data.CubeFace(face).Target()).AllocCompressed(int32(i), c.InternalFormat, w, h, 1, img)
+			}
+		case c.Layers > 0:
+			tex.AllocCompressed(int32(i), c.InternalFormat, w, h, int32(c.Layers), bytes.Join(level.Images, nil))
+		default:
+			tex.AllocCompressed(int32(i), c.InternalFormat, w, h, int32(level.Depth), level.Images[0])
+		}
+	}
+	//Incomplete mipmap chains are valid
+	gl.TexParameteri(uint32(tex.Target), gl.TEXTURE_MAX_LEVEL, int32(len(c.Levels)-1))
+	return data.CheckGlError("glCompressedTexImage")
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,182 @@
+package texfile_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/data"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/Qendolin/go-printpixel/internal/texfile"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	test.ParseArgs()
+	m.Run()
+}
+
+//Every byte of the data is its offset from the start of the data, modulo 256
+func sequence(n int) []byte {
+	seq := make([]byte, n)
+	for i := range seq {
+		seq[i] = byte(i)
+	}
+	return seq
+}
+
+func ddsFile(width, height, levels, caps2 int, fourCC string, dx10 []uint32, dataSize int) []byte {
+	header := make([]uint32, 31)
+	header[0] = 124
+	header[1] = 0x1007 | 0x20000
+	header[2] = uint32(height)
+	header[3] = uint32(width)
+	header[6] = uint32(levels)
+	header[18] = 32
+	header[19] = 0x4
+	header[20] = binary.LittleEndian.Uint32([]byte(fourCC))
+	header[27] = uint32(caps2)
+
+	var buf bytes.Buffer
+	buf.WriteString("DDS ")
+	binary.Write(&buf, binary.LittleEndian, header)
+	binary.Write(&buf, binary.LittleEndian, dx10)
+	buf.Write(sequence(dataSize))
+	return buf.Bytes()
+}
+
+func TestDecodeDds(t *testing.T) {
+	//16x8, 8x4 and 4x2 are 8, 2 and 1 blocks of 8 bytes
+	file := ddsFile(16, 8, 3, 0, "DXT1", nil, 64+16+8)
+	c, err := texfile.Decode(bytes.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(gl.COMPRESSED_RGBA_S3TC_DXT1_EXT), c.InternalFormat)
+	assert.Equal(t, []string{"GL_EXT_texture_compression_s3tc"}, c.Extensions)
+	assert.Equal(t, data.Texture2D, c.Target())
+	assert.Len(t, c.Levels, 3)
+	assert.Equal(t, 4, c.Levels[2].Width)
+	assert.Equal(t, 2, c.Levels[2].Height)
+	assert.Len(t, c.Levels[1].Images[0], 16)
+	assert.Equal(t, byte(64), c.Levels[1].Images[0][0])
+
+	_, err = texfile.DecodeDds(bytes.NewReader(file[:len(file)-1]))
+	assert.Equal(t, texfile.ErrTruncated, err)
+
+	_, err = texfile.DecodeDds(bytes.NewReader(ddsFile(4, 4, 1, 0, "ABCD", nil, 8)))
+	assert.Equal(t, texfile.FormatErr{Format: "ABCD"}, err)
+}
+
+func TestDecodeDdsCubeMap(t *testing.T) {
+	//BC7 with a DX10 header, each face has a 4x4 and a 2x2 level of one block
+	dx10 := []uint32{98, 3, 0x4, 1, 0}
+	file := ddsFile(4, 4, 2, 0x200|0xFC00, "DX10", dx10, 6*2*16)
+	c, err := texfile.DecodeDds(bytes.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(gl.COMPRESSED_RGBA_BPTC_UNORM_ARB), c.InternalFormat)
+	assert.Equal(t, data.TextureCubeMap, c.Target())
+	assert.Len(t, c.Levels[0].Images, 6)
+	//The faces are stored with their mipmap chain
+	assert.Equal(t, byte(32), c.Levels[0].Images[1][0])
+	assert.Equal(t, byte(16), c.Levels[1].Images[0][0])
+}
+
+func ktxFile(order binary.ByteOrder, internalFormat uint32, width, height, layers, faces, levels int, images [][]byte) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0xAB, 'K', 'T', 'X', ' ', '1', '1', 0xBB, '\r', '\n', 0x1A, '\n'})
+	binary.Write(&buf, order, []uint32{
+		0x04030201, 0, 1, 0, internalFormat, gl.RGBA,
+		uint32(width), uint32(height), 0, uint32(layers), uint32(faces), uint32(levels), 8,
+	})
+	buf.Write(make([]byte, 8))
+	for _, img := range images {
+		binary.Write(&buf, order, uint32(len(img)))
+		buf.Write(img)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeKtx(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		//An array of 2 layers with one 4x4 block each
+		file := ktxFile(order, gl.COMPRESSED_RG_RGTC2, 4, 4, 2, 1, 2, [][]byte{sequence(32), sequence(32)})
+		c, err := texfile.Decode(bytes.NewReader(file))
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(gl.COMPRESSED_RG_RGTC2), c.InternalFormat)
+		assert.Empty(t, c.Extensions)
+		assert.Equal(t, data.Texture2DArray, c.Target())
+		assert.Len(t, c.Levels, 2)
+		assert.Equal(t, byte(16), c.Levels[1].Images[1][0])
+	}
+
+	//ETC1 is uploaded as ETC2, which desktop OpenGL supports
+	file := ktxFile(binary.LittleEndian, 0x8D64, 4, 4, 0, 1, 1, [][]byte{sequence(8)})
+	c, err := texfile.DecodeKtx(bytes.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(gl.COMPRESSED_RGB8_ETC2), c.InternalFormat)
+	assert.Equal(t, []string{"GL_ARB_ES3_compatibility"}, c.Extensions)
+
+	file = ktxFile(binary.LittleEndian, gl.COMPRESSED_RGB8_ETC2, 4, 4, 0, 1, 1, [][]byte{sequence(9)})
+	_, err = texfile.DecodeKtx(bytes.NewReader(file))
+	assert.IsType(t, texfile.FormatErr{}, err)
+
+	_, err = texfile.Decode(bytes.NewReader([]byte("not a texture")))
+	assert.Equal(t, texfile.ErrUnknownContainer, err)
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	_, err := texfile.DecodeDds(bytes.NewReader(ddsFile(4, 4, 0xFFFFFFFF, 0, "DXT1", nil, 8)))
+	assert.Equal(t, texfile.HeaderErr{Field: "level count", Value: 0xFFFFFFFF}, err)
+
+	//4x4 has a 2x2 and a 1x1 level
+	_, err = texfile.DecodeDds(bytes.NewReader(ddsFile(4, 4, 4, 0, "DXT1", nil, 3*8)))
+	assert.Equal(t, texfile.HeaderErr{Field: "level count", Value: 4}, err)
+
+	_, err = texfile.DecodeDds(bytes.NewReader(ddsFile(0, 4, 1, 0, "DXT1", nil, 8)))
+	assert.Equal(t, texfile.HeaderErr{Field: "width", Value: 0}, err)
+
+	_, err = texfile.DecodeDds(bytes.NewReader(ddsFile(4, 1<<20, 1, 0, "DXT1", nil, 8)))
+	assert.Equal(t, texfile.HeaderErr{Field: "height", Value: 1 << 20}, err)
+
+	dx10 := []uint32{98, 3, 0, 0xFFFFFFFF, 0}
+	_, err = texfile.DecodeDds(bytes.NewReader(ddsFile(4, 4, 1, 0, "DX10", dx10, 16)))
+	assert.Equal(t, texfile.HeaderErr{Field: "layer count", Value: 0xFFFFFFFF}, err)
+
+	//The size is checked before the levels are created
+	_, err = texfile.DecodeDds(bytes.NewReader(ddsFile(1<<16, 1<<16, 17, 0, "DXT5", nil, 16)))
+	assert.Equal(t, texfile.ErrTruncated, err)
+
+	file := ktxFile(binary.LittleEndian, gl.COMPRESSED_RG_RGTC2, 4, 4, 0, 3, 1, [][]byte{sequence(16)})
+	_, err = texfile.DecodeKtx(bytes.NewReader(file))
+	assert.Equal(t, texfile.HeaderErr{Field: "face count", Value: 3}, err)
+
+	file = ktxFile(binary.LittleEndian, gl.COMPRESSED_RG_RGTC2, 0, 4, 0, 1, 1, [][]byte{sequence(16)})
+	_, err = texfile.DecodeKtx(bytes.NewReader(file))
+	assert.Equal(t, texfile.HeaderErr{Field: "width", Value: 0}, err)
+}
+
+func TestMissingExtension(t *testing.T) {
+	//The extension is checked before any gl call, without a context no extension is supported
+	c, err := texfile.DecodeDds(bytes.NewReader(ddsFile(4, 4, 1, 0, "DXT5", nil, 16)))
+	assert.NoError(t, err)
+	err = c.Upload(&data.Texture{Target: data.Texture2D})
+	assert.Equal(t, texfile.ExtensionErr{Extension: "GL_EXT_texture_compression_s3tc", Format: gl.COMPRESSED_RGBA_S3TC_DXT5_EXT}, err)
+}
+
+func TestUploadCompressed(t *testing.T) {
+	_, close := test.NewWindow(t)
+	defer close()
+
+	c, err := texfile.DecodeDds(bytes.NewReader(ddsFile(8, 8, 2, 0, "ATI1", nil, 4*8+8)))
+	assert.NoError(t, err)
+	tex := data.NewTexture(c.Target())
+	defer tex.Destroy()
+	tex.Bind(0)
+	assert.NoError(t, c.Upload(tex))
+
+	var compressed, maxLevel int32
+	gl.GetTexLevelParameteriv(gl.TEXTURE_2D, 1, gl.TEXTURE_COMPRESSED, &compressed)
+	gl.GetTexParameteriv(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, &maxLevel)
+	assert.Equal(t, int32(gl.TRUE), compressed)
+	assert.Equal(t, int32(1), maxLevel)
+}
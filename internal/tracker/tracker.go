@@ -0,0 +1,180 @@
+//Package tracker records the OpenGL objects that are created and not yet deleted, to find leaks.
+//Tracking is disabled by default and costs nothing but a lock when disabled.
+package tracker
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Kind int
+
+//Kinds of OpenGL objects
+const (
+	Texture = Kind(iota)
+	Buffer
+	VertexArray
+	Sampler
+	Program
+	Shader
+)
+
+var kindNames = [...]string{"Texture", "Buffer", "VertexArray", "Sampler", "Program", "Shader"}
+
+func (kind Kind) String() string {
+	if kind < 0 || int(kind) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", int(kind))
+	}
+	return kindNames[kind]
+}
+
+//An object that was created while tracking was enabled
+type Resource struct {
+	Kind Kind
+	Id   uint32
+	//The estimated size in bytes, the sum of all parts
+	Size int
+	//The stack trace of the creation
+	Stack string
+	parts map[int]int
+}
+
+func (res Resource) String() string {
+	return fmt.Sprintf("%v %v (%v bytes) created at\n%v", res.Kind, res.Id, res.Size, res.Stack)
+}
+
+type Total struct {
+	Count int
+	Bytes int
+}
+
+type key struct {
+	kind Kind
+	id   uint32
+}
+
+var (
+	mutex     sync.Mutex
+	enabled   bool
+	resources = map[key]*Resource{}
+)
+
+func Enable() {
+	mutex.Lock()
+	enabled = true
+	mutex.Unlock()
+}
+
+//Stops tracking, already tracked objects are kept
+func Disable() {
+	mutex.Lock()
+	enabled = false
+	mutex.Unlock()
+}
+
+func Enabled() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return enabled
+}
+
+//Forgets all tracked objects
+func Reset() {
+	mutex.Lock()
+	resources = map[key]*Resource{}
+	mutex.Unlock()
+}
+
+//Records the creation of an object, called by its constructor
+func Track(kind Kind, id uint32) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !enabled {
+		return
+	}
+	resources[key{kind, id}] = &Resource{
+		Kind:  kind,
+		Id:    id,
+		Stack: callers(),
+		parts: map[int]int{},
+	}
+}
+
+//Records the deletion of an object. Objects that weren't tracked are ignored.
+func Untrack(kind Kind, id uint32) {
+	mutex.Lock()
+	delete(resources, key{kind, id})
+	mutex.Unlock()
+}
+
+/*
+	Sets the estimated size of a part of an object. Objects that weren't tracked are ignored.
+
+	part - e.g. the mip level of a texture, buffers only have part 0
+*/
+func Resize(kind Kind, id uint32, part int, size int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	res, ok := resources[key{kind, id}]
+	if !ok {
+		return
+	}
+	res.Size += size - res.parts[part]
+	res.parts[part] = size
+}
+
+//The number and size of the tracked objects by kind
+func Totals() map[Kind]Total {
+	mutex.Lock()
+	defer mutex.Unlock()
+	totals := map[Kind]Total{}
+	for _, res := range resources {
+		total := totals[res.Kind]
+		total.Count++
+		total.Bytes += res.Size
+		totals[res.Kind] = total
+	}
+	return totals
+}
+
+//The objects that were created and not deleted, ordered by kind and id
+func Leaks() []Resource {
+	mutex.Lock()
+	leaks := make([]Resource, 0, len(resources))
+	for _, res := range resources {
+		leak := *res
+		leak.parts = nil
+		leaks = append(leaks, leak)
+	}
+	mutex.Unlock()
+
+	sort.Slice(leaks, func(i, j int) bool {
+		if leaks[i].Kind != leaks[j].Kind {
+			return leaks[i].Kind < leaks[j].Kind
+		}
+		return leaks[i].Id < leaks[j].Id
+	})
+	return leaks
+}
+
+//The stack of the caller of the constructor that called Track
+func callers() string {
+	pcs := make([]uintptr, 32)
+	//Skips runtime.Callers, callers and Track
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			fmt.Fprintf(&sb, "\t%v\n\t\t%v:%v\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
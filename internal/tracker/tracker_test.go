@@ -0,0 +1,54 @@
+package tracker_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/Qendolin/go-printpixel/internal/tracker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	test.ParseArgs()
+	m.Run()
+}
+
+func newTexture(id uint32) {
+	tracker.Track(tracker.Texture, id)
+}
+
+func TestTracker(t *testing.T) {
+	defer tracker.Reset()
+
+	tracker.Track(tracker.Texture, 1)
+	assert.Empty(t, tracker.Leaks(), "disabled")
+
+	tracker.Enable()
+	defer tracker.Disable()
+	newTexture(1)
+	newTexture(2)
+	tracker.Track(tracker.Buffer, 1)
+	tracker.Resize(tracker.Texture, 1, 0, 100)
+	tracker.Resize(tracker.Texture, 1, 1, 25)
+	tracker.Resize(tracker.Texture, 1, 0, 200)
+	tracker.Resize(tracker.Buffer, 1, 0, 64)
+	tracker.Resize(tracker.Buffer, 5, 0, 64)
+
+	assert.Equal(t, map[tracker.Kind]tracker.Total{
+		tracker.Texture: {Count: 2, Bytes: 225},
+		tracker.Buffer:  {Count: 1, Bytes: 64},
+	}, tracker.Totals())
+
+	tracker.Untrack(tracker.Texture, 2)
+	leaks := tracker.Leaks()
+	assert.Len(t, leaks, 2)
+	assert.Equal(t, tracker.Texture, leaks[0].Kind)
+	assert.Equal(t, 225, leaks[0].Size)
+	assert.True(t, strings.Contains(leaks[0].Stack, "tracker_test.newTexture"), leaks[0].Stack)
+	assert.Equal(t, tracker.Buffer, leaks[1].Kind)
+	assert.Equal(t, "Buffer", leaks[1].Kind.String())
+
+	tracker.Reset()
+	assert.Empty(t, tracker.Totals())
+}
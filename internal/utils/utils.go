@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"image"
+	"image/png"
 	"os"
 	"path/filepath"
 )
@@ -9,22 +11,34 @@ type BindingClosure func() []func()
 
 var rootPath string
 
+//Resolves a path relative to the module root, which is the closest parent of the working directory that contains a go.mod file
 func ResolveModulePath(path string) (absPath string, err error) {
 	if rootPath == "" {
 		var wd string
 		wd, err = os.Getwd()
 		if err != nil {
-			panic(err)
+			return
 		}
-		rootPath = filepath.Join(wd, "../..")
-	}
-	if err != nil {
-		return
+		rootPath = findModuleRoot(wd)
 	}
 	absPath = filepath.Join(rootPath, path)
 	return
 }
 
+//Falls back to two levels above dir, where the packages of this module are located
+func findModuleRoot(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return filepath.Join(dir, "../..")
+		}
+		current = parent
+	}
+}
+
 func MustResolveModulePath(path string) string {
 	absPath, err := ResolveModulePath(path)
 	if err != nil {
@@ -55,3 +69,16 @@ func NullTerm(str string) string {
 		return str + "\x00"
 	}
 }
+
+func SavePng(path string, img image.Image) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return png.Encode(file, img)
+}
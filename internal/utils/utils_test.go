@@ -1,7 +1,12 @@
 package utils_test
 
 import (
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Qendolin/go-printpixel/internal/test"
@@ -40,3 +45,25 @@ func TestNullTerminatedString(t *testing.T) {
 	assert.Equal(t, nullStr, utils.NullTerm(str))
 	assert.Equal(t, nullStr, utils.NullTerm(nullStr))
 }
+
+func TestSavePng(t *testing.T) {
+	dir, err := ioutil.TempDir("", "printpixel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.SetRGBA(1, 2, color.RGBA{10, 20, 30, 255})
+	path := filepath.Join(dir, "test.png")
+	assert.NoError(t, utils.SavePng(path, img))
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	decoded, err := png.Decode(file)
+	assert.NoError(t, err)
+	assert.Equal(t, color.RGBA{10, 20, 30, 255}, color.RGBAModel.Convert(decoded.At(1, 2)))
+}
@@ -0,0 +1,179 @@
+//Package loop runs a render loop with fixed timestep updates and variable rate drawing.
+package loop
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Qendolin/go-printpixel/mainthread"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+//The window that is presented after every frame, e.g. a *glfw.Window
+type Window interface {
+	ShouldClose() bool
+	SwapBuffers()
+}
+
+//Called at a fixed rate with dt being the update interval
+type UpdateFunc func(dt time.Duration)
+
+/*
+	Called once per frame.
+
+	dt - the time since the last frame
+	alpha - the progress towards the next update in [0, 1), can be used to interpolate between updates
+*/
+type DrawFunc func(dt time.Duration, alpha float64)
+
+type Config struct {
+	//The fixed time between updates, defaults to 1/60s
+	UpdateInterval time.Duration
+	//The maximum number of updates per frame, so slow updates can't stall drawing. Defaults to 5.
+	MaxUpdates int
+	//Limits the frames per second, unlimited if 0
+	MaxFps float64
+	//Processes window events after every frame, defaults to calling glfw.PollEvents on the main thread
+	PollEvents func()
+}
+
+type Stats struct {
+	Frames  uint64
+	Updates uint64
+	//Updates that were skipped because MaxUpdates was reached
+	DroppedUpdates uint64
+	//Moving average of the time between frames
+	FrameTime time.Duration
+	//Moving average of the frames per second
+	Fps float64
+	//The time since the loop was started
+	Elapsed time.Duration
+}
+
+//The weight of the newest frame in the moving averages
+const statsSmoothing = 0.1
+
+type Loop struct {
+	Config
+	stats      Stats
+	statsMutex sync.Mutex
+}
+
+func New(cfg Config) *Loop {
+	if cfg.UpdateInterval <= 0 {
+		cfg.UpdateInterval = time.Second / 60
+	}
+	if cfg.MaxUpdates <= 0 {
+		cfg.MaxUpdates = 5
+	}
+	if cfg.PollEvents == nil {
+		cfg.PollEvents = func() {
+			mainthread.Call(glfw.PollEvents)
+		}
+	}
+	return &Loop{Config: cfg}
+}
+
+//Runs a loop with the default configuration. See Loop.Run
+func Run(ctx context.Context, win Window, update UpdateFunc, draw DrawFunc) error {
+	return New(Config{}).Run(ctx, win, update, draw)
+}
+
+//Returns the statistics of the running or last run. Safe to call from any goroutine.
+func (l *Loop) Stats() Stats {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+	return l.stats
+}
+
+/*
+	Runs until the window should close, which returns nil, or ctx is done, which returns ctx.Err().
+	Has to be called from the thread of the window's context, unless the window executes its calls through the mainthread package.
+
+	update, draw - may be nil
+*/
+func (l *Loop) Run(ctx context.Context, win Window, update UpdateFunc, draw DrawFunc) error {
+	l.statsMutex.Lock()
+	l.stats = Stats{}
+	l.statsMutex.Unlock()
+
+	var frameLimit time.Duration
+	if l.MaxFps > 0 {
+		frameLimit = time.Duration(float64(time.Second) / l.MaxFps)
+	}
+
+	start := time.Now()
+	last := start
+	var lag time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if win.ShouldClose() {
+			return nil
+		}
+
+		frameStart := time.Now()
+		dt := frameStart.Sub(last)
+		last = frameStart
+		lag += dt
+
+		var updates, dropped uint64
+		for lag >= l.UpdateInterval {
+			if updates == uint64(l.MaxUpdates) {
+				dropped = uint64(lag / l.UpdateInterval)
+				lag %= l.UpdateInterval
+				break
+			}
+			if update != nil {
+				update(l.UpdateInterval)
+			}
+			lag -= l.UpdateInterval
+			updates++
+		}
+
+		if draw != nil {
+			draw(dt, float64(lag)/float64(l.UpdateInterval))
+		}
+		win.SwapBuffers()
+		l.PollEvents()
+
+		l.record(dt, updates, dropped, frameStart.Sub(start))
+
+		if frameLimit > 0 {
+			if remaining := frameLimit - time.Since(frameStart); remaining > 0 {
+				timer := time.NewTimer(remaining)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+				case <-timer.C:
+				}
+			}
+		}
+	}
+}
+
+func (l *Loop) record(dt time.Duration, updates, dropped uint64, elapsed time.Duration) {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	l.stats.Frames++
+	l.stats.Updates += updates
+	l.stats.DroppedUpdates += dropped
+	l.stats.Elapsed = elapsed
+	//The first frame has no meaningful delta
+	if l.stats.Frames == 1 {
+		return
+	}
+	if l.stats.FrameTime == 0 {
+		l.stats.FrameTime = dt
+	} else {
+		l.stats.FrameTime = time.Duration(float64(l.stats.FrameTime)*(1-statsSmoothing) + float64(dt)*statsSmoothing)
+	}
+	if l.stats.FrameTime > 0 {
+		l.stats.Fps = float64(time.Second) / float64(l.stats.FrameTime)
+	}
+}
@@ -0,0 +1,91 @@
+package loop_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/Qendolin/go-printpixel/loop"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	test.ParseArgs()
+	m.Run()
+}
+
+type fakeWindow struct {
+	frames    int
+	maxFrames int
+}
+
+func (win *fakeWindow) ShouldClose() bool {
+	return win.frames >= win.maxFrames
+}
+
+func (win *fakeWindow) SwapBuffers() {
+	win.frames++
+}
+
+func TestRunUntilClosed(t *testing.T) {
+	win := &fakeWindow{maxFrames: 10}
+	l := loop.New(loop.Config{
+		UpdateInterval: time.Millisecond,
+		MaxFps:         200,
+		PollEvents:     func() {},
+	})
+
+	draws := 0
+	var updateTime time.Duration
+	err := l.Run(context.Background(), win, func(dt time.Duration) {
+		assert.Equal(t, time.Millisecond, dt)
+		updateTime += dt
+	}, func(dt time.Duration, alpha float64) {
+		assert.True(t, alpha >= 0 && alpha < 1)
+		draws++
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, draws)
+	stats := l.Stats()
+	assert.Equal(t, uint64(10), stats.Frames)
+	assert.Equal(t, stats.Updates, uint64(updateTime/time.Millisecond))
+	assert.True(t, stats.Updates > 0)
+	//The frame rate is capped at 200 fps
+	assert.True(t, stats.FrameTime >= 4*time.Millisecond, "frame time %v", stats.FrameTime)
+	assert.True(t, stats.Fps > 0 && stats.Fps <= 250, "fps %v", stats.Fps)
+}
+
+func TestRunCancel(t *testing.T) {
+	win := &fakeWindow{maxFrames: 1000}
+	l := loop.New(loop.Config{PollEvents: func() {}, MaxFps: 100})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := l.Run(ctx, win, nil, func(time.Duration, float64) {
+		if win.frames == 3 {
+			cancel()
+		}
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 4, win.frames)
+}
+
+func TestMaxUpdates(t *testing.T) {
+	win := &fakeWindow{maxFrames: 3}
+	l := loop.New(loop.Config{
+		UpdateInterval: time.Millisecond,
+		MaxUpdates:     1,
+		PollEvents:     func() {},
+	})
+
+	err := l.Run(context.Background(), win, nil, func(time.Duration, float64) {
+		time.Sleep(5 * time.Millisecond)
+	})
+
+	assert.NoError(t, err)
+	stats := l.Stats()
+	assert.Equal(t, uint64(2), stats.Updates)
+	assert.True(t, stats.DroppedUpdates > 0)
+}
@@ -0,0 +1,160 @@
+/*
+	Package mainthread executes functions on the main thread.
+
+	OpenGL and GLFW calls have to be made from the thread that created the context, which usually is the main thread.
+	Call Run from main and use Call, CallErr or CallAsync from any goroutine to execute functions on it.
+*/
+package mainthread
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+var (
+	//Guards running, mainID and queue, so calls can't be queued after Run executed the last ones
+	mutex   sync.Mutex
+	running bool
+	//The goroutine that executes Run
+	mainID uint64
+	queue  []func()
+	//Signals Run that calls were queued
+	wake = make(chan struct{}, 1)
+)
+
+func init() {
+	//Keeps the main goroutine on the main thread
+	runtime.LockOSThread()
+}
+
+//Executes run in a new goroutine and the queued calls on the current thread until run returns.
+//Has to be called from the main goroutine, usually from main.
+func Run(run func()) {
+	mutex.Lock()
+	running = true
+	mainID = goroutineID()
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		running = false
+		mutex.Unlock()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run()
+	}()
+
+	for {
+		select {
+		case <-wake:
+			executeQueued()
+		case <-done:
+			//No calls are queued once running is false, so the remaining ones are executed here
+			mutex.Lock()
+			running = false
+			mutex.Unlock()
+			executeQueued()
+			return
+		}
+	}
+}
+
+func executeQueued() {
+	mutex.Lock()
+	calls := queue
+	queue = nil
+	mutex.Unlock()
+	for _, f := range calls {
+		f()
+	}
+}
+
+//Queues f if Run is executing. Returns false if f has to be called directly instead.
+func enqueue(f func()) bool {
+	mutex.Lock()
+	if !running {
+		mutex.Unlock()
+		return false
+	}
+	queue = append(queue, f)
+	mutex.Unlock()
+
+	select {
+	case wake <- struct{}{}:
+	default:
+		//Run is already signaled
+	}
+	return true
+}
+
+//Reports whether Run is executing
+func Running() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return running
+}
+
+//Reports whether Run is executing on the current goroutine, so calls can't be queued
+func onMainThread() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return running && goroutineID() == mainID
+}
+
+//Parses the id from the first line of the stack trace, e.g. "goroutine 1 [running]:"
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i != -1 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+/*
+	Executes f on the main thread and waits for it to return. Panics in f are propagated to the caller.
+	If Run isn't executing or the caller is on the main thread f is called directly.
+*/
+func Call(f func()) {
+	if onMainThread() {
+		f()
+		return
+	}
+
+	done := make(chan interface{})
+	queued := enqueue(func() {
+		defer func() {
+			done <- recover()
+		}()
+		f()
+	})
+	if !queued {
+		f()
+		return
+	}
+	if p := <-done; p != nil {
+		panic(p)
+	}
+}
+
+//Like Call but returns the error of f
+func CallErr(f func() error) (err error) {
+	Call(func() {
+		err = f()
+	})
+	return
+}
+
+//Queues f for execution on the main thread without waiting for it.
+//If Run isn't executing or the caller is on the main thread f is called directly.
+func CallAsync(f func()) {
+	if onMainThread() || !enqueue(f) {
+		f()
+	}
+}
@@ -0,0 +1,118 @@
+package mainthread_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/Qendolin/go-printpixel/mainthread"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	test.ParseArgs()
+	m.Run()
+}
+
+func TestCallWithoutRun(t *testing.T) {
+	called := false
+	mainthread.Call(func() {
+		called = true
+	})
+	assert.True(t, called)
+	assert.False(t, mainthread.Running())
+}
+
+func TestRun(t *testing.T) {
+	counter := 0
+	asyncCounter := 0
+	mainthread.Run(func() {
+		assert.True(t, mainthread.Running())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					//The calls are serialized, so no synchronization is needed
+					mainthread.Call(func() {
+						counter++
+					})
+					mainthread.CallAsync(func() {
+						asyncCounter++
+					})
+				}
+			}()
+		}
+		wg.Wait()
+
+		err := mainthread.CallErr(func() error {
+			return errors.New("test")
+		})
+		assert.EqualError(t, err, "test")
+
+		assert.Panics(t, func() {
+			mainthread.Call(func() {
+				panic("test")
+			})
+		})
+	})
+
+	assert.False(t, mainthread.Running())
+	assert.Equal(t, 1000, counter)
+	assert.Equal(t, 1000, asyncCounter)
+}
+
+func TestCallOnMainThread(t *testing.T) {
+	order := []int{}
+	mainthread.Run(func() {
+		mainthread.Call(func() {
+			//Would deadlock if queued, the main thread is busy executing this call
+			mainthread.Call(func() {
+				order = append(order, 0)
+			})
+			for i := 1; i <= 100; i++ {
+				i := i
+				mainthread.CallAsync(func() {
+					order = append(order, i)
+				})
+			}
+		})
+	})
+	assert.Len(t, order, 101)
+	assert.Equal(t, 100, order[100])
+}
+
+func TestCallWhileRunReturns(t *testing.T) {
+	var issued, executed int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				//Calls must neither block nor get lost when Run returns concurrently
+				atomic.AddInt64(&issued, 1)
+				mainthread.CallAsync(func() {
+					atomic.AddInt64(&executed, 1)
+				})
+				mainthread.Call(func() {})
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		mainthread.Run(func() {})
+	}
+	close(stop)
+	wg.Wait()
+	assert.Equal(t, atomic.LoadInt64(&issued), atomic.LoadInt64(&executed))
+}
@@ -0,0 +1,85 @@
+package printpixel
+
+import (
+	"image/color"
+
+	"github.com/Qendolin/go-printpixel/internal/canvas"
+	"github.com/Qendolin/go-printpixel/mainthread"
+)
+
+type PaletteCycle = canvas.PaletteCycle
+
+//A PalettedSurface is a Surface that stores one palette index per pixel.
+//The colors are looked up on the gpu, so palette changes are cheap.
+type PalettedSurface struct {
+	*Surface
+	pal *canvas.PalettedCanvas
+}
+
+/*
+	palette - at most 256 colors
+	opts - may be nil
+*/
+func OpenPaletted(width, height int, palette color.Palette, opts *Options) (surf *PalettedSurface, err error) {
+	var pal *canvas.PalettedCanvas
+	var base *Surface
+	mainthread.Call(func() {
+		base, err = open(width, height, opts, func() (surfaceCanvas, *canvas.Canvas, error) {
+			var err error
+			pal, err = canvas.NewPalettedCanvas(width, height, palette)
+			if err != nil {
+				return nil, nil, err
+			}
+			return pal, pal.Canvas, nil
+		})
+	})
+	if err != nil {
+		return
+	}
+	return &PalettedSurface{Surface: base, pal: pal}, nil
+}
+
+func (surf *PalettedSurface) ColorIndexAt(x, y int) uint8 {
+	surf.mutex.Lock()
+	defer surf.mutex.Unlock()
+	return surf.pal.ColorIndexAt(x, y)
+}
+
+func (surf *PalettedSurface) SetColorIndex(x, y int, index uint8) {
+	surf.mutex.Lock()
+	surf.pal.SetColorIndex(x, y, index)
+	surf.mutex.Unlock()
+}
+
+//Returns a copy of the current palette
+func (surf *PalettedSurface) Palette() color.Palette {
+	surf.mutex.Lock()
+	defer surf.mutex.Unlock()
+	return append(color.Palette(nil), surf.pal.Palette()...)
+}
+
+func (surf *PalettedSurface) SetPalette(palette color.Palette) error {
+	surf.mutex.Lock()
+	defer surf.mutex.Unlock()
+	return surf.pal.SetPalette(palette)
+}
+
+//Rotates the colors low to high (inclusive) by steps towards higher indices, negative steps rotate towards lower indices
+func (surf *PalettedSurface) CyclePalette(low, high, steps int) {
+	surf.mutex.Lock()
+	surf.pal.CyclePalette(low, high, steps)
+	surf.mutex.Unlock()
+}
+
+//Adds a palette cycle that advances while the surface is drawn
+func (surf *PalettedSurface) AddCycle(cycle PaletteCycle) {
+	surf.mutex.Lock()
+	surf.pal.AddCycle(cycle)
+	surf.mutex.Unlock()
+}
+
+func (surf *PalettedSurface) ClearCycles() {
+	surf.mutex.Lock()
+	surf.pal.ClearCycles()
+	surf.mutex.Unlock()
+}
@@ -0,0 +1,254 @@
+//Package printpixel provides a simple way to "print" pixels to a window.
+package printpixel
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"github.com/Qendolin/go-printpixel/internal/assets"
+	"github.com/Qendolin/go-printpixel/internal/canvas"
+	"github.com/Qendolin/go-printpixel/internal/context"
+	"github.com/Qendolin/go-printpixel/internal/tracker"
+	"github.com/Qendolin/go-printpixel/internal/utils"
+	"github.com/Qendolin/go-printpixel/internal/window"
+	"github.com/Qendolin/go-printpixel/mainthread"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+type ScaleMode = canvas.ScaleMode
+
+const (
+	ScaleStretch = canvas.ScaleStretch
+	ScaleFit     = canvas.ScaleFit
+	ScaleFill    = canvas.ScaleFill
+	ScaleInteger = canvas.ScaleInteger
+	ScaleCenter  = canvas.ScaleCenter
+)
+
+type Options struct {
+	//The window title, defaults to "printpixel"
+	Title string
+	//The size of a surface pixel in screen pixels, defaults to 1
+	Scale int
+	//How the pixels are scaled when the window size doesn't match, defaults to ScaleStretch
+	ScaleMode ScaleMode
+	//The color of the bars around the pixels, defaults to black
+	BarColor  color.Color
+	Resizable bool
+	Hidden    bool
+	//A directory with assets that override the built-in ones, e.g. "assets/shaders/quad_tex.frag"
+	AssetDir string
+	//Records the created OpenGL objects and logs those that were not deleted when the surface is closed
+	TrackResources bool
+}
+
+/*
+	A Surface is a window with a pixel buffer that is displayed on Present. It implements draw.Image.
+	Only one Surface can be open at a time.
+
+	The methods can be called from any goroutine if mainthread.Run is executing, they are then executed on the main thread.
+	Otherwise they have to be called from the main thread.
+*/
+type Surface struct {
+	win   *glfw.Window
+	cnv   surfaceCanvas
+	base  *canvas.Canvas
+	mutex sync.Mutex
+}
+
+//The canvases a Surface can display, like canvas.PixelCanvas
+type surfaceCanvas interface {
+	draw.Image
+	Draw()
+	Destroy()
+}
+
+//Returns the canvas and the Canvas it is based on
+type canvasConstructor func() (surfaceCanvas, *canvas.Canvas, error)
+
+/*
+	opts - may be nil
+*/
+func Open(width, height int, opts *Options) (surf *Surface, err error) {
+	mainthread.Call(func() {
+		surf, err = open(width, height, opts, func() (surfaceCanvas, *canvas.Canvas, error) {
+			cnv, err := canvas.NewPixelCanvas(width, height)
+			if err != nil {
+				return nil, nil, err
+			}
+			return cnv, cnv.Canvas, nil
+		})
+	})
+	return
+}
+
+func open(width, height int, opts *Options, newCanvas canvasConstructor) (surf *Surface, err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	title := opts.Title
+	if title == "" {
+		title = "printpixel"
+	}
+	scale := opts.Scale
+	if scale < 1 {
+		scale = 1
+	}
+
+	if opts.TrackResources {
+		tracker.Enable()
+	}
+
+	if err = context.InitGlfw(); err != nil {
+		return
+	}
+
+	hints := window.NewHints()
+	hints.Resizable.Value = opts.Resizable
+	hints.Visible.Value = !opts.Hidden
+	hints.OpenGLProfile.Value = window.OpenGLCoreProfile
+	hints.OpenGLForwardCompatible.Value = true
+	win, err := window.New(hints, title, width*scale, height*scale, nil)
+	if err != nil {
+		context.Terminate()
+		return
+	}
+
+	win.MakeContextCurrent()
+	if err = context.InitGl(context.NewGlConfig(0)); err != nil {
+		win.Destroy()
+		context.Terminate()
+		return
+	}
+
+	cnv, base, err := newCanvasFrom(opts.AssetDir, newCanvas)
+	if err != nil {
+		win.Destroy()
+		context.Terminate()
+		return
+	}
+	base.ScaleMode = opts.ScaleMode
+	base.BarColor = opts.BarColor
+	if base.BarColor == nil {
+		base.BarColor = color.Black
+	}
+	base.Resize(win.GetFramebufferSize())
+
+	surf = &Surface{
+		win:  win,
+		cnv:  cnv,
+		base: base,
+	}
+	win.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
+		surf.mutex.Lock()
+		base.Resize(width, height)
+		surf.mutex.Unlock()
+	})
+	return
+}
+
+//The shaders are loaded when the canvas is created, so assets.Default is only overridden for that
+func newCanvasFrom(assetDir string, newCanvas canvasConstructor) (surfaceCanvas, *canvas.Canvas, error) {
+	if assetDir == "" {
+		return newCanvas()
+	}
+	prev := assets.Default
+	assets.Default = assets.Overlay{assets.Dir(assetDir), prev}
+	defer func() { assets.Default = prev }()
+	return newCanvas()
+}
+
+func (surf *Surface) SetScaleMode(mode ScaleMode) {
+	surf.mutex.Lock()
+	surf.base.ScaleMode = mode
+	surf.mutex.Unlock()
+}
+
+func (surf *Surface) ColorModel() color.Model {
+	surf.mutex.Lock()
+	defer surf.mutex.Unlock()
+	return surf.cnv.ColorModel()
+}
+
+func (surf *Surface) Bounds() image.Rectangle {
+	return surf.cnv.Bounds()
+}
+
+func (surf *Surface) At(x, y int) color.Color {
+	surf.mutex.Lock()
+	defer surf.mutex.Unlock()
+	return surf.cnv.At(x, y)
+}
+
+//Only changes the pixel buffer, so it doesn't have to wait for the main thread
+func (surf *Surface) Set(x, y int, c color.Color) {
+	surf.mutex.Lock()
+	surf.cnv.Set(x, y, c)
+	surf.mutex.Unlock()
+}
+
+//Uploads the pixel buffer, displays it and processes pending window events.
+func (surf *Surface) Present() {
+	mainthread.Call(func() {
+		surf.draw()
+		surf.win.SwapBuffers()
+		glfw.PollEvents()
+	})
+}
+
+//Uploads the pixel buffer and draws it to the back buffer
+func (surf *Surface) Draw() {
+	mainthread.Call(surf.draw)
+}
+
+func (surf *Surface) draw() {
+	surf.mutex.Lock()
+	defer surf.mutex.Unlock()
+	surf.base.BindFor(func() []func() {
+		surf.cnv.Draw()
+		return nil
+	})
+}
+
+//Displays the back buffer. Together with ShouldClose this makes the surface a loop.Window.
+func (surf *Surface) SwapBuffers() {
+	mainthread.Call(surf.win.SwapBuffers)
+}
+
+//Draws the pixel buffer and reads back the window contents
+func (surf *Surface) Snapshot() (img *image.RGBA, err error) {
+	mainthread.Call(func() {
+		surf.mutex.Lock()
+		defer surf.mutex.Unlock()
+		surf.base.BindFor(func() []func() {
+			surf.cnv.Draw()
+			img, err = surf.base.Snapshot()
+			return nil
+		})
+	})
+	return
+}
+
+//Saves a snapshot of the window contents as png
+func (surf *Surface) SavePng(path string) error {
+	img, err := surf.Snapshot()
+	if err != nil {
+		return err
+	}
+	return utils.SavePng(path, img)
+}
+
+//Reports whether the user requested the window to be closed. Can be called from any goroutine.
+func (surf *Surface) ShouldClose() bool {
+	return surf.win.ShouldClose()
+}
+
+func (surf *Surface) Close() {
+	mainthread.Call(func() {
+		surf.cnv.Destroy()
+		surf.win.Destroy()
+		context.Terminate()
+	})
+}
@@ -0,0 +1,110 @@
+package printpixel_test
+
+import (
+	"context"
+	"image/color"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	printpixel "github.com/Qendolin/go-printpixel"
+	"github.com/Qendolin/go-printpixel/internal/test"
+	"github.com/Qendolin/go-printpixel/loop"
+	"github.com/Qendolin/go-printpixel/mainthread"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	test.ParseArgs()
+	m.Run()
+}
+
+func TestSurface(t *testing.T) {
+	runtime.LockOSThread()
+	surf, err := printpixel.Open(64, 36, &printpixel.Options{Scale: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer surf.Close()
+
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 36; y++ {
+			surf.Set(x, y, color.RGBA{uint8(x * 4), uint8(y * 7), 0, 255})
+		}
+	}
+
+	for i := 0; i < 10 && !surf.ShouldClose(); i++ {
+		surf.Present()
+	}
+}
+
+func TestSurfaceLoop(t *testing.T) {
+	runtime.LockOSThread()
+	surf, err := printpixel.Open(64, 36, &printpixel.Options{Scale: 10, ScaleMode: printpixel.ScaleFit, Resizable: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer surf.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	x := 0
+	err = loop.Run(ctx, surf, func(dt time.Duration) {
+		x = (x + 1) % 64
+	}, func(dt time.Duration, alpha float64) {
+		surf.Set(x, 18, color.White)
+		surf.Draw()
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestSurfaceMainThread(t *testing.T) {
+	runtime.LockOSThread()
+	mainthread.Run(func() {
+		surf, err := printpixel.Open(64, 36, &printpixel.Options{Scale: 10})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer surf.Close()
+
+		var wg sync.WaitGroup
+		for row := 0; row < 36; row++ {
+			wg.Add(1)
+			go func(y int) {
+				defer wg.Done()
+				for x := 0; x < 64; x++ {
+					surf.Set(x, y, color.RGBA{uint8(x * 4), uint8(y * 7), 255, 255})
+				}
+			}(row)
+		}
+		wg.Wait()
+
+		for i := 0; i < 10 && !surf.ShouldClose(); i++ {
+			surf.Present()
+		}
+	})
+}
+
+func TestPalettedSurface(t *testing.T) {
+	runtime.LockOSThread()
+	palette := color.Palette{color.Black, color.RGBA{255, 0, 0, 255}, color.RGBA{255, 255, 0, 255}, color.White}
+	surf, err := printpixel.OpenPaletted(64, 36, palette, &printpixel.Options{Scale: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer surf.Close()
+
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 36; y++ {
+			surf.SetColorIndex(x, y, uint8(1+(x+y)%3))
+		}
+	}
+	surf.AddCycle(printpixel.PaletteCycle{Low: 1, High: 3, Interval: 50 * time.Millisecond})
+
+	for i := 0; i < 10 && !surf.ShouldClose(); i++ {
+		surf.Present()
+	}
+}